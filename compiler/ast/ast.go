@@ -2,12 +2,14 @@ package ast
 
 import (
 	"bytes"
+	"sort"
 	"wolf404/compiler/lexer"
 )
 
 type Node interface {
 	TokenLiteral() string
 	String() string
+	Pos() lexer.Token // token the node starts at, for error positions
 }
 
 type Statement interface {
@@ -31,6 +33,13 @@ func (p *Program) TokenLiteral() string {
 	return ""
 }
 
+func (p *Program) Pos() lexer.Token {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].Pos()
+	}
+	return lexer.Token{}
+}
+
 func (p *Program) String() string {
 	var out bytes.Buffer
 	for _, s := range p.Statements {
@@ -47,6 +56,7 @@ type Identifier struct {
 
 func (i *Identifier) expressionNode()      {}
 func (i *Identifier) TokenLiteral() string { return i.Token.Literal }
+func (i *Identifier) Pos() lexer.Token     { return i.Token }
 func (i *Identifier) String() string       { return i.Value }
 
 // Expressions
@@ -58,8 +68,19 @@ type IntegerLiteral struct {
 
 func (il *IntegerLiteral) expressionNode()      {}
 func (il *IntegerLiteral) TokenLiteral() string { return il.Token.Literal }
+func (il *IntegerLiteral) Pos() lexer.Token     { return il.Token }
 func (il *IntegerLiteral) String() string       { return il.Token.Literal }
 
+type FloatLiteral struct {
+	Token lexer.Token
+	Value float64
+}
+
+func (fl *FloatLiteral) expressionNode()      {}
+func (fl *FloatLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FloatLiteral) Pos() lexer.Token     { return fl.Token }
+func (fl *FloatLiteral) String() string       { return fl.Token.Literal }
+
 type StringLiteral struct {
 	Token lexer.Token
 	Value string
@@ -67,8 +88,21 @@ type StringLiteral struct {
 
 func (sl *StringLiteral) expressionNode()      {}
 func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Literal }
+func (sl *StringLiteral) Pos() lexer.Token     { return sl.Token }
 func (sl *StringLiteral) String() string       { return "\"" + sl.Token.Literal + "\"" }
 
+// InterpolatedString is a string literal with embedded expressions, such as
+// "Halo {$jeneng}". Parts alternate between StringLiterals and expressions.
+type InterpolatedString struct {
+	Token lexer.Token
+	Parts []Expression
+}
+
+func (is *InterpolatedString) expressionNode()      {}
+func (is *InterpolatedString) TokenLiteral() string { return is.Token.Literal }
+func (is *InterpolatedString) Pos() lexer.Token     { return is.Token }
+func (is *InterpolatedString) String() string       { return is.Token.Literal }
+
 type Boolean struct {
 	Token lexer.Token
 	Value bool
@@ -76,28 +110,59 @@ type Boolean struct {
 
 func (b *Boolean) expressionNode()      {}
 func (b *Boolean) TokenLiteral() string { return b.Token.Literal }
+func (b *Boolean) Pos() lexer.Token     { return b.Token }
 func (b *Boolean) String() string       { return b.Token.Literal }
 
+type NilLiteral struct {
+	Token lexer.Token
+}
+
+func (nl *NilLiteral) expressionNode()      {}
+func (nl *NilLiteral) TokenLiteral() string { return nl.Token.Literal }
+func (nl *NilLiteral) Pos() lexer.Token     { return nl.Token }
+func (nl *NilLiteral) String() string       { return nl.Token.Literal }
+
 // Statements
 
+// LetStatement is lokal $name = value, defining name in the current scope
+// even when an enclosing function already has a variable of that name.
 type LetStatement struct {
-	Token lexer.Token // the TOKEN_DOLLAR token
+	Token lexer.Token // 'local'
 	Name  *Identifier
-	Value Expression
+	Value Expression // nil for a bare lokal $name
 }
 
 func (ls *LetStatement) statementNode()       {}
 func (ls *LetStatement) TokenLiteral() string { return ls.Token.Literal }
+func (ls *LetStatement) Pos() lexer.Token     { return ls.Token }
 func (ls *LetStatement) String() string {
 	var out bytes.Buffer
-	out.WriteString(ls.Token.Literal + ls.Name.Value)
-	out.WriteString(" = ")
+	out.WriteString("local $" + ls.Name.Value)
 	if ls.Value != nil {
+		out.WriteString(" = ")
 		out.WriteString(ls.Value.String())
 	}
 	return out.String()
 }
 
+// GlobalStatement is jagad $a, $b: inside a function the names refer to
+// module variables, so assigning them updates the module scope.
+type GlobalStatement struct {
+	Token lexer.Token // 'global'
+	Names []*Identifier
+}
+
+func (gs *GlobalStatement) statementNode()       {}
+func (gs *GlobalStatement) TokenLiteral() string { return gs.Token.Literal }
+func (gs *GlobalStatement) Pos() lexer.Token     { return gs.Token }
+func (gs *GlobalStatement) String() string {
+	names := []string{}
+	for _, name := range gs.Names {
+		names = append(names, "$"+name.Value)
+	}
+	return "global " + join(names, ", ")
+}
+
 type ReturnStatement struct {
 	Token       lexer.Token // the 'bring' token
 	ReturnValue Expression
@@ -105,6 +170,7 @@ type ReturnStatement struct {
 
 func (rs *ReturnStatement) statementNode()       {}
 func (rs *ReturnStatement) TokenLiteral() string { return rs.Token.Literal }
+func (rs *ReturnStatement) Pos() lexer.Token     { return rs.Token }
 func (rs *ReturnStatement) String() string {
 	var out bytes.Buffer
 	out.WriteString(rs.Token.Literal + " ")
@@ -121,6 +187,7 @@ type ExpressionStatement struct {
 
 func (es *ExpressionStatement) statementNode()       {}
 func (es *ExpressionStatement) TokenLiteral() string { return es.Token.Literal }
+func (es *ExpressionStatement) Pos() lexer.Token     { return es.Token }
 func (es *ExpressionStatement) String() string {
 	if es.Expression != nil {
 		return es.Expression.String()
@@ -135,6 +202,7 @@ type BlockStatement struct {
 
 func (bs *BlockStatement) statementNode()       {}
 func (bs *BlockStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BlockStatement) Pos() lexer.Token     { return bs.Token }
 func (bs *BlockStatement) String() string {
 	var out bytes.Buffer
 	for _, s := range bs.Statements {
@@ -146,12 +214,15 @@ func (bs *BlockStatement) String() string {
 type ClassStatement struct {
 	Token      lexer.Token // 'mold'
 	Name       *Identifier
-	SuperClass *Identifier // For inheritance
+	SuperClass *Identifier   // For inheritance
+	Interfaces []*Identifier // netepi Kontrak, ...
 	Body       *BlockStatement
+	Doc        string // /// comment above the declaration
 }
 
 func (cs *ClassStatement) statementNode()       {}
 func (cs *ClassStatement) TokenLiteral() string { return cs.Token.Literal }
+func (cs *ClassStatement) Pos() lexer.Token     { return cs.Token }
 func (cs *ClassStatement) String() string {
 	var out bytes.Buffer
 	out.WriteString("mold ")
@@ -161,20 +232,149 @@ func (cs *ClassStatement) String() string {
 	return out.String()
 }
 
+// StaticStatement declares a class-level member inside a gerombolan body:
+// statis garap name(...) or statis $name = value.
+type StaticStatement struct {
+	Token    lexer.Token      // 'static'
+	Function *FunctionLiteral // set for static methods
+	Name     *Identifier      // set for static properties
+	Value    Expression       // nil for a bare statis $name
+}
+
+func (ss *StaticStatement) statementNode()       {}
+func (ss *StaticStatement) TokenLiteral() string { return ss.Token.Literal }
+func (ss *StaticStatement) Pos() lexer.Token     { return ss.Token }
+func (ss *StaticStatement) String() string {
+	if ss.Function != nil {
+		return "static " + ss.Function.String()
+	}
+	out := "static $" + ss.Name.Value
+	if ss.Value != nil {
+		out += " = " + ss.Value.String()
+	}
+	return out
+}
+
+// ConstStatement is tetep NAME = value inside a gerombolan body.
+type ConstStatement struct {
+	Token lexer.Token // 'const'
+	Name  *Identifier
+	Value Expression
+}
+
+func (cs *ConstStatement) statementNode()       {}
+func (cs *ConstStatement) TokenLiteral() string { return cs.Token.Literal }
+func (cs *ConstStatement) Pos() lexer.Token     { return cs.Token }
+func (cs *ConstStatement) String() string {
+	return "const " + cs.Name.Value + " = " + cs.Value.String()
+}
+
+// InterfaceStatement declares a kontrak: the methods a class must define
+// to netepi it. Parents are kontrak whose methods are required too.
+type InterfaceStatement struct {
+	Token   lexer.Token // 'interface'
+	Name    *Identifier
+	Parents []*Identifier
+	Methods []*InterfaceMethod
+	Doc     string
+}
+
+func (is *InterfaceStatement) statementNode()       {}
+func (is *InterfaceStatement) TokenLiteral() string { return is.Token.Literal }
+func (is *InterfaceStatement) Pos() lexer.Token     { return is.Token }
+func (is *InterfaceStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString("interface ")
+	out.WriteString(is.Name.String())
+	for _, m := range is.Methods {
+		out.WriteString(" ")
+		out.WriteString(m.String())
+	}
+	return out.String()
+}
+
+// InterfaceMethod is a method signature inside a kontrak, without a body.
+type InterfaceMethod struct {
+	Token      lexer.Token // 'hunt'
+	Name       string
+	Parameters []*Parameter
+}
+
+func (im *InterfaceMethod) String() string {
+	params := []string{}
+	for _, p := range im.Parameters {
+		params = append(params, p.String())
+	}
+	return im.Name + "(" + join(params, ", ") + ")"
+}
+
+// TraitStatement declares a sifat: methods that classes mix in with nyampur.
+type TraitStatement struct {
+	Token lexer.Token // 'trait'
+	Name  *Identifier
+	Body  *BlockStatement
+	Doc   string
+}
+
+func (ts *TraitStatement) statementNode()       {}
+func (ts *TraitStatement) TokenLiteral() string { return ts.Token.Literal }
+func (ts *TraitStatement) Pos() lexer.Token     { return ts.Token }
+func (ts *TraitStatement) String() string {
+	return "trait " + ts.Name.String() + " " + ts.Body.String()
+}
+
+// UseStatement mixes traits into the enclosing gerombolan: nyampur A, B.
+type UseStatement struct {
+	Token  lexer.Token // 'use'
+	Traits []*Identifier
+}
+
+func (us *UseStatement) statementNode()       {}
+func (us *UseStatement) TokenLiteral() string { return us.Token.Literal }
+func (us *UseStatement) Pos() lexer.Token     { return us.Token }
+func (us *UseStatement) String() string {
+	names := []string{}
+	for _, t := range us.Traits {
+		names = append(names, t.String())
+	}
+	return "use " + join(names, ", ")
+}
+
 type SummonStatement struct {
 	Token lexer.Token // 'summon'
 	Path  *StringLiteral
+	Alias *Identifier // undang "path" dadi Alias; nil binds the exports directly
 }
 
 func (ss *SummonStatement) statementNode()       {}
 func (ss *SummonStatement) TokenLiteral() string { return ss.Token.Literal }
+func (ss *SummonStatement) Pos() lexer.Token     { return ss.Token }
 func (ss *SummonStatement) String() string {
 	var out bytes.Buffer
 	out.WriteString("summon ")
 	out.WriteString(ss.Path.String())
+	if ss.Alias != nil {
+		out.WriteString(" as ")
+		out.WriteString(ss.Alias.String())
+	}
 	return out.String()
 }
 
+// ExportStatement marks a top-level declaration as visible to files that
+// import the module. Name is the binding it exports.
+type ExportStatement struct {
+	Token     lexer.Token // 'export'
+	Statement Statement
+	Name      string
+}
+
+func (es *ExportStatement) statementNode()       {}
+func (es *ExportStatement) TokenLiteral() string { return es.Token.Literal }
+func (es *ExportStatement) Pos() lexer.Token     { return es.Token }
+func (es *ExportStatement) String() string {
+	return "export " + es.Statement.String()
+}
+
 type ProwlStatement struct {
 	Token lexer.Token // 'prowl'
 	Call  Expression  // The function call
@@ -182,6 +382,7 @@ type ProwlStatement struct {
 
 func (ps *ProwlStatement) statementNode()       {}
 func (ps *ProwlStatement) TokenLiteral() string { return ps.Token.Literal }
+func (ps *ProwlStatement) Pos() lexer.Token     { return ps.Token }
 func (ps *ProwlStatement) String() string {
 	var out bytes.Buffer
 	out.WriteString("prowl ")
@@ -194,29 +395,112 @@ func (ps *ProwlStatement) String() string {
 type TrackStatement struct {
 	Token     lexer.Token // 'track'
 	Condition Expression
-	Body      *BlockStatement
+
+	// For-in form (track $k, $v in $items); Iterable is nil for while loops.
+	// Value may be an array or hash pattern that destructures each item.
+	Key      *Identifier
+	Value    Expression
+	Iterable Expression
+
+	Body *BlockStatement
 }
 
 func (ts *TrackStatement) statementNode()       {}
 func (ts *TrackStatement) TokenLiteral() string { return ts.Token.Literal }
+func (ts *TrackStatement) Pos() lexer.Token     { return ts.Token }
 func (ts *TrackStatement) String() string {
 	var out bytes.Buffer
 	out.WriteString("track ")
-	out.WriteString(ts.Condition.String())
+	if ts.Iterable != nil {
+		if ts.Key != nil {
+			out.WriteString(ts.Key.String() + ", ")
+		}
+		out.WriteString(ts.Value.String())
+		out.WriteString(" in ")
+		out.WriteString(ts.Iterable.String())
+	} else {
+		out.WriteString(ts.Condition.String())
+	}
 	out.WriteString(" ")
 	out.WriteString(ts.Body.String())
 	return out.String()
 }
 
+type BreakStatement struct {
+	Token lexer.Token // 'break'
+}
+
+func (bs *BreakStatement) statementNode()       {}
+func (bs *BreakStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BreakStatement) Pos() lexer.Token     { return bs.Token }
+func (bs *BreakStatement) String() string       { return bs.Token.Literal }
+
+type ContinueStatement struct {
+	Token lexer.Token // 'continue'
+}
+
+func (cs *ContinueStatement) statementNode()       {}
+func (cs *ContinueStatement) TokenLiteral() string { return cs.Token.Literal }
+func (cs *ContinueStatement) Pos() lexer.Token     { return cs.Token }
+func (cs *ContinueStatement) String() string       { return cs.Token.Literal }
+
+type TryStatement struct {
+	Token      lexer.Token // 'try'
+	Body       *BlockStatement
+	CatchParam *Identifier // optional: catch $e
+	Catch      *BlockStatement
+	Finally    *BlockStatement
+}
+
+func (ts *TryStatement) statementNode()       {}
+func (ts *TryStatement) TokenLiteral() string { return ts.Token.Literal }
+func (ts *TryStatement) Pos() lexer.Token     { return ts.Token }
+func (ts *TryStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString("try ")
+	out.WriteString(ts.Body.String())
+	if ts.Catch != nil {
+		out.WriteString(" catch ")
+		if ts.CatchParam != nil {
+			out.WriteString(ts.CatchParam.String() + " ")
+		}
+		out.WriteString(ts.Catch.String())
+	}
+	if ts.Finally != nil {
+		out.WriteString(" finally ")
+		out.WriteString(ts.Finally.String())
+	}
+	return out.String()
+}
+
+type ThrowStatement struct {
+	Token lexer.Token // 'throw'
+	Value Expression
+}
+
+func (ts *ThrowStatement) statementNode()       {}
+func (ts *ThrowStatement) TokenLiteral() string { return ts.Token.Literal }
+func (ts *ThrowStatement) Pos() lexer.Token     { return ts.Token }
+func (ts *ThrowStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString(ts.Token.Literal + " ")
+	if ts.Value != nil {
+		out.WriteString(ts.Value.String())
+	}
+	return out.String()
+}
+
 type FunctionLiteral struct {
 	Token      lexer.Token // 'hunt'
 	Name       string
-	Parameters []*Identifier
+	Parameters []*Parameter
 	Body       *BlockStatement
+	Doc        string // /// comment above the declaration
 }
 
 func (fl *FunctionLiteral) expressionNode()      {}
 func (fl *FunctionLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FunctionLiteral) Pos() lexer.Token     { return fl.Token }
 func (fl *FunctionLiteral) String() string {
 	var out bytes.Buffer
 	params := []string{}
@@ -236,6 +520,45 @@ func (fl *FunctionLiteral) String() string {
 	return out.String()
 }
 
+// Parameter is one entry of a garap parameter list: $name, $name = default,
+// ...$name collecting the remaining positional arguments, or a destructuring
+// pattern such as [$x, $y].
+type Parameter struct {
+	Token   lexer.Token
+	Name    *Identifier
+	Pattern Expression // array or hash pattern; Name is nil when set
+	Default Expression // nil when the parameter is required
+	Rest    bool
+}
+
+func (p *Parameter) String() string {
+	var out string
+	if p.Pattern != nil {
+		out = p.Pattern.String()
+	} else {
+		out = "$" + p.Name.String()
+	}
+	if p.Rest {
+		out = "..." + out
+	}
+	if p.Default != nil {
+		out += " = " + p.Default.String()
+	}
+	return out
+}
+
+// NamedArgument is a name: value argument at a call site.
+type NamedArgument struct {
+	Token lexer.Token // the name
+	Name  string
+	Value Expression
+}
+
+func (na *NamedArgument) expressionNode()      {}
+func (na *NamedArgument) TokenLiteral() string { return na.Token.Literal }
+func (na *NamedArgument) Pos() lexer.Token     { return na.Token }
+func (na *NamedArgument) String() string       { return na.Name + ": " + na.Value.String() }
+
 type CallExpression struct {
 	Token     lexer.Token // '('
 	Function  Expression  // Identifier or FunctionLiteral
@@ -244,6 +567,7 @@ type CallExpression struct {
 
 func (ce *CallExpression) expressionNode()      {}
 func (ce *CallExpression) TokenLiteral() string { return ce.Token.Literal }
+func (ce *CallExpression) Pos() lexer.Token     { return ce.Function.Pos() }
 func (ce *CallExpression) String() string {
 	var out bytes.Buffer
 	out.WriteString(ce.Function.String())
@@ -258,7 +582,16 @@ func (ce *CallExpression) String() string {
 	return out.String()
 }
 
+// SuperExpression is induk inside a method: induk.name(...) calls the parent
+// class's version of a method and induk(...) calls the parent's init.
+type SuperExpression struct {
+	Token lexer.Token // 'super'
+}
 
+func (se *SuperExpression) expressionNode()      {}
+func (se *SuperExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SuperExpression) Pos() lexer.Token     { return se.Token }
+func (se *SuperExpression) String() string       { return "super" }
 
 type ArrayLiteral struct {
 	Token    lexer.Token // '['
@@ -267,6 +600,7 @@ type ArrayLiteral struct {
 
 func (al *ArrayLiteral) expressionNode()      {}
 func (al *ArrayLiteral) TokenLiteral() string { return al.Token.Literal }
+func (al *ArrayLiteral) Pos() lexer.Token     { return al.Token }
 func (al *ArrayLiteral) String() string {
 	var out bytes.Buffer
 	elements := []string{}
@@ -284,13 +618,30 @@ type HashLiteral struct {
 	Pairs map[Expression]Expression
 }
 
+// Keys returns the literal's keys in source order.
+func (hl *HashLiteral) Keys() []Expression {
+	keys := make([]Expression, 0, len(hl.Pairs))
+	for key := range hl.Pairs {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i].Pos(), keys[j].Pos()
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return keys
+}
+
 func (hl *HashLiteral) expressionNode()      {}
 func (hl *HashLiteral) TokenLiteral() string { return hl.Token.Literal }
+func (hl *HashLiteral) Pos() lexer.Token     { return hl.Token }
 func (hl *HashLiteral) String() string {
 	var out bytes.Buffer
 	pairs := []string{}
-	for key, value := range hl.Pairs {
-		pairs = append(pairs, key.String()+":"+value.String())
+	for _, key := range hl.Keys() {
+		pairs = append(pairs, key.String()+":"+hl.Pairs[key].String())
 	}
 	out.WriteString("{")
 	out.WriteString(join(pairs, ", "))
@@ -306,6 +657,7 @@ type IndexExpression struct {
 
 func (ie *IndexExpression) expressionNode()      {}
 func (ie *IndexExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IndexExpression) Pos() lexer.Token     { return ie.Token }
 func (ie *IndexExpression) String() string {
 	var out bytes.Buffer
 	out.WriteString("(")
@@ -316,6 +668,38 @@ func (ie *IndexExpression) String() string {
 	return out.String()
 }
 
+// SliceExpression is $x[start:end:step]; any of the three may be nil.
+type SliceExpression struct {
+	Token lexer.Token // The [ token
+	Left  Expression
+	Start Expression
+	End   Expression
+	Step  Expression
+}
+
+func (se *SliceExpression) expressionNode()      {}
+func (se *SliceExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SliceExpression) Pos() lexer.Token     { return se.Token }
+func (se *SliceExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("(")
+	out.WriteString(se.Left.String())
+	out.WriteString("[")
+	if se.Start != nil {
+		out.WriteString(se.Start.String())
+	}
+	out.WriteString(":")
+	if se.End != nil {
+		out.WriteString(se.End.String())
+	}
+	if se.Step != nil {
+		out.WriteString(":")
+		out.WriteString(se.Step.String())
+	}
+	out.WriteString("])")
+	return out.String()
+}
+
 func join(strs []string, sep string) string {
 	if len(strs) == 0 {
 		return ""
@@ -338,6 +722,7 @@ type InfixExpression struct {
 
 func (oe *InfixExpression) expressionNode()      {}
 func (oe *InfixExpression) TokenLiteral() string { return oe.Token.Literal }
+func (oe *InfixExpression) Pos() lexer.Token     { return oe.Token }
 func (oe *InfixExpression) String() string {
 	var out bytes.Buffer
 	out.WriteString("(")
@@ -348,24 +733,194 @@ func (oe *InfixExpression) String() string {
 	return out.String()
 }
 
+type PrefixExpression struct {
+	Token    lexer.Token // The prefix token, e.g. - or ora
+	Operator string
+	Right    Expression
+}
+
+func (pe *PrefixExpression) expressionNode()      {}
+func (pe *PrefixExpression) TokenLiteral() string { return pe.Token.Literal }
+func (pe *PrefixExpression) Pos() lexer.Token     { return pe.Token }
+func (pe *PrefixExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("(")
+	out.WriteString(pe.Operator)
+	if pe.Operator == "not" {
+		out.WriteString(" ")
+	}
+	out.WriteString(pe.Right.String())
+	out.WriteString(")")
+	return out.String()
+}
+
 type IfExpression struct {
 	Token       lexer.Token // The 'sniff' token
 	Condition   Expression
 	Consequence *BlockStatement
+	ElseIf      *IfExpression   // for 'missing sniff <cond>' chains
 	Alternative *BlockStatement // for 'missing'
 }
 
 func (ie *IfExpression) expressionNode()      {}
 func (ie *IfExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IfExpression) Pos() lexer.Token     { return ie.Token }
 func (ie *IfExpression) String() string {
 	var out bytes.Buffer
 	out.WriteString("sniff ")
 	out.WriteString(ie.Condition.String())
 	out.WriteString(" ")
 	out.WriteString(ie.Consequence.String())
-	if ie.Alternative != nil {
+	if ie.ElseIf != nil {
+		out.WriteString(" missing ")
+		out.WriteString(ie.ElseIf.String())
+	} else if ie.Alternative != nil {
 		out.WriteString(" missing ")
 		out.WriteString(ie.Alternative.String())
 	}
 	return out.String()
 }
+
+// MatchExpression is cocokno $subject followed by an indented list of arms.
+// The first arm whose pattern matches and whose guard holds is evaluated.
+type MatchExpression struct {
+	Token   lexer.Token // 'cocokno'
+	Subject Expression
+	Arms    []*MatchArm
+}
+
+func (me *MatchExpression) expressionNode()      {}
+func (me *MatchExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MatchExpression) Pos() lexer.Token     { return me.Token }
+func (me *MatchExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("match ")
+	out.WriteString(me.Subject.String())
+	for _, arm := range me.Arms {
+		out.WriteString(" ")
+		out.WriteString(arm.String())
+	}
+	return out.String()
+}
+
+// MatchArm is pattern, pattern menowo guard => body. Single-expression
+// bodies are wrapped in a block.
+type MatchArm struct {
+	Token    lexer.Token // first token of the arm
+	Patterns []Pattern   // alternatives; any one of them may match
+	Guard    Expression  // nil without menowo
+	Body     *BlockStatement
+}
+
+func (ma *MatchArm) String() string {
+	patterns := []string{}
+	for _, p := range ma.Patterns {
+		patterns = append(patterns, p.String())
+	}
+	out := join(patterns, ", ")
+	if ma.Guard != nil {
+		out += " sniff " + ma.Guard.String()
+	}
+	return out + " => " + ma.Body.String()
+}
+
+// Pattern is the left side of a match arm.
+type Pattern interface {
+	Node
+	patternNode()
+}
+
+// WildcardPattern is _, matching anything without binding it.
+type WildcardPattern struct {
+	Token lexer.Token
+}
+
+func (wp *WildcardPattern) patternNode()         {}
+func (wp *WildcardPattern) TokenLiteral() string { return wp.Token.Literal }
+func (wp *WildcardPattern) Pos() lexer.Token     { return wp.Token }
+func (wp *WildcardPattern) String() string       { return "_" }
+
+// BindingPattern is $name, matching anything and binding it to name.
+type BindingPattern struct {
+	Token lexer.Token // '$'
+	Name  *Identifier
+}
+
+func (bp *BindingPattern) patternNode()         {}
+func (bp *BindingPattern) TokenLiteral() string { return bp.Token.Literal }
+func (bp *BindingPattern) Pos() lexer.Token     { return bp.Token }
+func (bp *BindingPattern) String() string       { return "$" + bp.Name.String() }
+
+// LiteralPattern matches values equal to a number, string, boolean, nil or
+// gerombolan constant (Status.AKTIF).
+type LiteralPattern struct {
+	Token lexer.Token
+	Value Expression
+}
+
+func (lp *LiteralPattern) patternNode()         {}
+func (lp *LiteralPattern) TokenLiteral() string { return lp.Token.Literal }
+func (lp *LiteralPattern) Pos() lexer.Token     { return lp.Token }
+func (lp *LiteralPattern) String() string       { return lp.Value.String() }
+
+// TypePattern matches values of a type (STRING, HASH, ...) or instances of a
+// class, optionally binding the value: INTEGER $n.
+type TypePattern struct {
+	Token    lexer.Token // the type name
+	TypeName string
+	Binding  *Identifier // nil when the value is not bound
+}
+
+func (tp *TypePattern) patternNode()         {}
+func (tp *TypePattern) TokenLiteral() string { return tp.Token.Literal }
+func (tp *TypePattern) Pos() lexer.Token     { return tp.Token }
+func (tp *TypePattern) String() string {
+	if tp.Binding != nil {
+		return tp.TypeName + " $" + tp.Binding.String()
+	}
+	return tp.TypeName
+}
+
+// ArrayPattern matches arrays element by element. Without a rest element
+// the lengths must be equal; ...$rest collects the remaining elements.
+type ArrayPattern struct {
+	Token    lexer.Token // '['
+	Elements []Pattern
+	HasRest  bool
+	Rest     *Identifier // nil for a bare ...
+}
+
+func (ap *ArrayPattern) patternNode()         {}
+func (ap *ArrayPattern) TokenLiteral() string { return ap.Token.Literal }
+func (ap *ArrayPattern) Pos() lexer.Token     { return ap.Token }
+func (ap *ArrayPattern) String() string {
+	elements := []string{}
+	for _, el := range ap.Elements {
+		elements = append(elements, el.String())
+	}
+	if ap.Rest != nil {
+		elements = append(elements, "...$"+ap.Rest.String())
+	} else if ap.HasRest {
+		elements = append(elements, "...")
+	}
+	return "[" + join(elements, ", ") + "]"
+}
+
+// HashPattern matches hashes that have every listed key with a matching
+// value; other keys are ignored.
+type HashPattern struct {
+	Token  lexer.Token // '{'
+	Keys   []Expression
+	Values []Pattern
+}
+
+func (hp *HashPattern) patternNode()         {}
+func (hp *HashPattern) TokenLiteral() string { return hp.Token.Literal }
+func (hp *HashPattern) Pos() lexer.Token     { return hp.Token }
+func (hp *HashPattern) String() string {
+	pairs := []string{}
+	for i, key := range hp.Keys {
+		pairs = append(pairs, key.String()+":"+hp.Values[i].String())
+	}
+	return "{" + join(pairs, ", ") + "}"
+}
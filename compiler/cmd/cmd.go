@@ -61,8 +61,13 @@ hunt main()
 }
 
 func RunFile(args []string) {
+	// --ketat (or --strict) makes out-of-range indexing an error
+	if len(args) > 0 && (args[0] == "--ketat" || args[0] == "--strict") {
+		evaluator.StrictIndexing = true
+		args = args[1:]
+	}
 	if len(args) == 0 {
-		fmt.Println("Usage: wlf gas <file.wlf> or wlf gas server")
+		fmt.Println("Usage: wlf gas [--ketat] <file.wlf> or wlf gas server")
 		return
 	}
 	filename := args[0]
@@ -87,15 +92,18 @@ func RunFile(args []string) {
 
 	// Parse
 	fmt.Println("Starting Lexer/Parser...")
-	l := lexer.New(string(content))
+	l := lexer.NewWithFile(string(content), filename)
 	p := parser.New(l)
 	program := p.ParseProgram()
 	fmt.Printf("Parsed %d statements\n", len(program.Statements))
 
 	if len(p.Errors()) != 0 {
-		printParserErrors(p.Errors())
+		printParserErrors(p.Errors(), string(content))
 		return
 	}
+	if len(p.Warnings()) != 0 {
+		fmt.Print(parser.FormatWarnings(p.Warnings(), string(content)))
+	}
 
 	// Evaluate
 	fmt.Println("\n--- AST Structure ---")
@@ -109,8 +117,8 @@ func RunFile(args []string) {
 	if evaluated != nil {
 		// Only print result if it's an error or significant?
 		// Usually programs print via 'howl', so returning inspection is strictly for REPL.
-		if evaluated.Type() == object.ERROR_OBJ {
-			fmt.Println(evaluated.Inspect())
+		if err, ok := evaluated.(*object.Error); ok {
+			fmt.Println(evaluator.FormatTraceback(err))
 		}
 	}
 }
@@ -187,12 +195,13 @@ func RunMigrations(args []string) {
 				continue
 			}
 
-			l := lexer.New(string(content))
+			l := lexer.NewWithFile(string(content), migrationPath)
 			p := parser.New(l)
 			program := p.ParseProgram()
 
 			if len(p.Errors()) != 0 {
 				fmt.Printf("   ❌ Parse errors in %s\n", file.Name())
+				fmt.Print(parser.FormatErrors(p.Errors(), string(content)))
 				continue
 			}
 
@@ -233,7 +242,7 @@ func MakeController(args []string) {
 	controllerContent := fmt.Sprintf(`// app/Controllers/%s.wlf
 // Created by ishowpen
 
-gerombolan %s
+ekspor gerombolan %s
     garap index($req)
         balekno http_json({"message": "Index method"})
     
@@ -277,7 +286,9 @@ func MakeMiddleware(args []string) {
 	middlewareContent := fmt.Sprintf(`// app/Middleware/%s.wlf
 // Created by ishowpen
 
-gerombolan %s
+undang "system/Middleware.wlf"
+
+ekspor gerombolan %s netepi Middleware
     garap handle($request)
         // Add your middleware logic here
         balekno {"error": salah}
@@ -317,17 +328,17 @@ func MakeModel(args []string) {
 	modelContent := fmt.Sprintf(`// app/Models/%s.wlf
 // Created by ishowpen
 
-gerombolan %s
+ekspor gerombolan %s
     garap init($db)
         $this.db = $db
         $this.table = "%s"
     
     garap all()
-        $query = "SELECT * FROM " + $this.table
+        $query = "SELECT * FROM $this.table"
         balekno db_query($query)
     
     garap find($id)
-        $query = "SELECT * FROM " + $this.table + " WHERE id = ?"
+        $query = "SELECT * FROM $this.table WHERE id = ?"
         balekno db_query($query)
     
     garap create($data)
@@ -339,7 +350,7 @@ gerombolan %s
         balekno bener
     
     garap delete($id)
-        $query = "DELETE FROM " + $this.table + " WHERE id = ?"
+        $query = "DELETE FROM $this.table WHERE id = ?"
         balekno db_exec($query)
 `, modelName, modelName, strings.ToLower(modelName)+"s")
 
@@ -366,11 +377,13 @@ gerombolan %s
 // Created by ishowpen
 
 garap up($db)
-    $sql = "CREATE TABLE IF NOT EXISTS %s (
-        id INTEGER PRIMARY KEY AUTOINCREMENT,
-        created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-        updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
-    )"
+    $sql = """
+        CREATE TABLE IF NOT EXISTS %s (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+        )
+    """
     db_exec($sql)
     ketok("✅ Table %s created")
 
@@ -396,9 +409,7 @@ garap down($db)
 	fmt.Println("\n🐺 Model and Migration created successfully!")
 }
 
-func printParserErrors(errors []string) {
+func printParserErrors(errors []*parser.ParseError, source string) {
 	fmt.Println("🐺 Wolf404 encountered errors during parsing:")
-	for _, msg := range errors {
-		fmt.Printf("\t%s\n", msg)
-	}
+	fmt.Print(parser.FormatErrors(errors, source))
 }
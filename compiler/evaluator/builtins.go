@@ -12,7 +12,9 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"wolf404/compiler/lexer"
 	"wolf404/compiler/object"
@@ -34,12 +36,22 @@ func isSafePath(path string) bool {
 var dbConnections = make(map[string]*sql.DB)
 var sessions = make(map[string]object.Object)
 
+// MaxRangeLength is the most elements deret builds, so a typo like
+// deret(1000000000000) fails instead of exhausting memory.
+const MaxRangeLength = 10_000_000
+
 func convertToWolfObject(v interface{}) object.Object {
 	switch val := v.(type) {
 	case int64:
 		return &object.Integer{Value: val}
 	case float64:
-		return &object.String{Value: fmt.Sprintf("%f", val)}
+		return &object.Float{Value: val}
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return &object.Integer{Value: i}
+		}
+		f, _ := val.Float64()
+		return &object.Float{Value: f}
 	case string:
 		return &object.String{Value: val}
 	case []byte:
@@ -66,16 +78,44 @@ func convertToWolfObject(v interface{}) object.Object {
 	}
 }
 
+// convertToNative is the inverse of convertToWolfObject, used for JSON
+// encoding and for binding query parameters.
+func convertToNative(obj object.Object) interface{} {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return obj.Value
+	case *object.Float:
+		return obj.Value
+	case *object.String:
+		return obj.Value
+	case *object.Boolean:
+		return obj.Value
+	case *object.Null:
+		return nil
+	case *object.Array:
+		elements := make([]interface{}, len(obj.Elements))
+		for i, el := range obj.Elements {
+			elements[i] = convertToNative(el)
+		}
+		return elements
+	case *object.Hash:
+		m := make(map[string]interface{}, len(obj.Pairs))
+		for _, pair := range obj.Pairs {
+			m[pair.Key.Inspect()] = convertToNative(pair.Value)
+		}
+		return m
+	default:
+		return obj.Inspect()
+	}
+}
+
 var builtins map[string]*object.Builtin
 
 func init() {
 	builtins = map[string]*object.Builtin{
 		"ketok": {
 			Fn: func(args ...object.Object) object.Object {
-				for _, arg := range args {
-					fmt.Println(arg.Inspect())
-				}
-				return NULL
+				return applyHowl(args)
 			},
 		},
 		"eval_wolf": {
@@ -99,7 +139,7 @@ func init() {
 					}
 				}
 
-				l := lexer.New(code.Value)
+				l := lexer.NewWithFile(code.Value, "<eval_wolf>")
 				p := parser.New(l)
 				program := p.ParseProgram()
 				if len(p.Errors()) != 0 {
@@ -218,39 +258,78 @@ func init() {
 				csrfInput := fmt.Sprintf("<input type='hidden' name='_token' value='%s'>", token)
 				raw = strings.ReplaceAll(raw, "@csrf", csrfInput)
 
-				reEscaped := regexp.MustCompile(`{{\s*(.*?)\s*}}`)
-				reRaw := regexp.MustCompile(`{!!\s*(.*?)\s*!!}`)
-				reYen := regexp.MustCompile(`@yen\s*\((.*?)\)`)
-				raw = reYen.ReplaceAllString(raw, " menowo $1 { ")
-				raw = strings.ReplaceAll(raw, "@punkyan_yen", " } ")
-
-				reTrack := regexp.MustCompile(`@track_neng\s*\((.*?)\s+neng\s+(.*?)\)`)
-				raw = reTrack.ReplaceAllString(raw, " $_items = $2; $_len = dowo($_items); $_i = 0; track $_i < $_len { $1 = $_items[$_i]; ")
-				raw = strings.ReplaceAll(raw, "@punkyan_track", " $_i = $_i + 1; } ")
-
-				lines := strings.Split(raw, "\n")
+				// Directives open and close indented blocks in the generated
+				// code. A line holding only a directive produces no output.
 				var buffer bytes.Buffer
-				buffer.WriteString("$_out = ''; ")
-
-				for _, line := range lines {
-					trimmed := strings.TrimSpace(line)
-					if strings.HasPrefix(trimmed, "menowo") || strings.HasPrefix(trimmed, "track") || trimmed == "}" || strings.HasSuffix(trimmed, "}") || trimmed == "" {
-						buffer.WriteString(line + "\n")
-					} else {
-						l := strings.ReplaceAll(line, "\"", "\\\"")
-						l = reEscaped.ReplaceAllString(l, "\" + html_escape($1) + \"")
-						l = reRaw.ReplaceAllString(l, "\" + string($1) + \"")
-						buffer.WriteString("$_out = $_out + \"" + l + "\\n\";\n")
+				depth := 0
+				emptyBlock := false
+				emit := func(code string) {
+					buffer.WriteString(strings.Repeat("    ", depth) + code + "\n")
+					emptyBlock = false
+				}
+				closeBlock := func() {
+					if emptyBlock {
+						emit("kopong")
+					}
+					if depth > 0 {
+						depth--
 					}
 				}
-				buffer.WriteString("balekno $_out")
+
+				emit("$_out = \"\"")
+				for _, line := range strings.Split(raw, "\n") {
+					parts, err := splitTemplateLine(line)
+					if err != nil {
+						return err
+					}
+					// Keep the line break unless the line was a lone directive
+					switch n := len(parts); {
+					case n == 1 && parts[0].directive != "":
+					case n > 0 && parts[n-1].directive == "":
+						parts[n-1].text += "\n"
+					default:
+						parts = append(parts, templatePart{text: "\n"})
+					}
+					for _, part := range parts {
+						switch part.directive {
+						case "":
+							if part.text != "" {
+								emit("$_out = $_out + " + compileTemplateLine(part.text))
+							}
+						case "@yen":
+							emit("menowo " + part.arg)
+							depth++
+							emptyBlock = true
+						case "@yenora_yen", "@yenora":
+							// Close the current branch and open the next one at the same depth
+							closeBlock()
+							if part.directive == "@yenora" {
+								emit("yenora")
+							} else {
+								emit("yenora menowo " + part.arg)
+							}
+							depth++
+							emptyBlock = true
+						case "@track_neng":
+							emit("baleni " + part.arg)
+							depth++
+							emptyBlock = true
+						case "@punkyan_yen", "@punkyan_track":
+							closeBlock()
+						}
+					}
+				}
+				if depth != 0 {
+					return newError("render_template: @yen/@track_neng ora ditutup")
+				}
+				emit("balekno $_out")
 
 				env := object.NewEnvironment()
 				for k, v := range data {
 					env.Set(k, v)
 				}
 
-				l := lexer.New(buffer.String())
+				l := lexer.NewWithFile(buffer.String(), "<template>")
 				p := parser.New(l)
 				prog := p.ParseProgram()
 				if len(p.Errors()) > 0 {
@@ -267,7 +346,8 @@ func init() {
 				}
 				switch arg := args[0].(type) {
 				case *object.String:
-					return &object.Integer{Value: int64(len(arg.Value))}
+					// Characters, not bytes; string_byte_len counts bytes
+					return &object.Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
 				case *object.Array:
 					return &object.Integer{Value: int64(len(arg.Elements))}
 				case *object.Hash:
@@ -277,6 +357,50 @@ func init() {
 				}
 			},
 		},
+		"deret": {
+			Fn: func(args ...object.Object) object.Object {
+				if len(args) < 1 || len(args) > 3 {
+					return newError("deret butuhe 1 nganti 3 argumen")
+				}
+				bounds := make([]int64, len(args))
+				for i, arg := range args {
+					n, ok := arg.(*object.Integer)
+					if !ok {
+						return newError("argumen deret kudu Integer")
+					}
+					bounds[i] = n.Value
+				}
+
+				start, stop, step := int64(0), bounds[0], int64(1)
+				if len(bounds) > 1 {
+					start, stop = bounds[0], bounds[1]
+				}
+				if len(bounds) > 2 {
+					step = bounds[2]
+				}
+				if step == 0 {
+					return newError("step deret ora oleh nol")
+				}
+
+				// Count first, in unsigned arithmetic so wide ranges can't overflow
+				var count uint64
+				switch {
+				case step > 0 && stop > start:
+					count = (uint64(stop)-uint64(start)-1)/uint64(step) + 1
+				case step < 0 && stop < start:
+					count = (uint64(start)-uint64(stop)-1)/(-uint64(step)) + 1
+				}
+				if count > MaxRangeLength {
+					return newError("deret kegedhen: %d elemen, paling akeh %d", count, MaxRangeLength)
+				}
+
+				elements := make([]object.Object, count)
+				for i := range elements {
+					elements[i] = &object.Integer{Value: start + int64(i)*step}
+				}
+				return &object.Array{Elements: elements}
+			},
+		},
 		"plumbungan": {
 			Fn: func(args ...object.Object) object.Object {
 				if len(args) != 2 {
@@ -295,7 +419,7 @@ func init() {
 				if len(args) != 1 {
 					return newError("string butuhe 1 argumen")
 				}
-				return &object.String{Value: args[0].Inspect()}
+				return stringify(args[0])
 			},
 		},
 		"string_contains": {
@@ -347,12 +471,64 @@ func init() {
 				return &object.Array{Elements: elements}
 			},
 		},
+		"string_bytes": {
+			Fn: func(args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return newError("string_bytes butuhe 1 argumen")
+				}
+				str, ok := args[0].(*object.String)
+				if !ok {
+					return newError("argumen neng `string_bytes` kudu STRING, oleh %s", args[0].Type())
+				}
+				elements := make([]object.Object, len(str.Value))
+				for i := 0; i < len(str.Value); i++ {
+					elements[i] = &object.Integer{Value: int64(str.Value[i])}
+				}
+				return &object.Array{Elements: elements}
+			},
+		},
+		"string_from_bytes": {
+			Fn: func(args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return newError("string_from_bytes butuhe 1 argumen")
+				}
+				arr, ok := args[0].(*object.Array)
+				if !ok {
+					return newError("argumen neng `string_from_bytes` kudu ARRAY, oleh %s", args[0].Type())
+				}
+				buf := make([]byte, len(arr.Elements))
+				for i, el := range arr.Elements {
+					b, ok := el.(*object.Integer)
+					if !ok || b.Value < 0 || b.Value > 255 {
+						return newError("string_from_bytes: elemen %d dudu byte (0-255): %s", i, el.Inspect())
+					}
+					buf[i] = byte(b.Value)
+				}
+				return &object.String{Value: string(buf)}
+			},
+		},
+		"string_byte_len": {
+			Fn: func(args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return newError("string_byte_len butuhe 1 argumen")
+				}
+				str, ok := args[0].(*object.String)
+				if !ok {
+					return newError("argumen neng `string_byte_len` kudu STRING, oleh %s", args[0].Type())
+				}
+				return &object.Integer{Value: int64(len(str.Value))}
+			},
+		},
 		"html_escape": {
 			Fn: func(args ...object.Object) object.Object {
 				if len(args) != 1 {
 					return newError("html_escape butuhe 1 argumen")
 				}
-				return &object.String{Value: html.EscapeString(args[0].Inspect())}
+				str := stringify(args[0])
+				if isError(str) {
+					return str
+				}
+				return &object.String{Value: html.EscapeString(str.Inspect())}
 			},
 		},
 		"generate_token": {
@@ -375,6 +551,46 @@ func init() {
 				return &object.Array{Elements: elements}
 			},
 		},
+		"instance_of": {
+			Fn: func(args ...object.Object) object.Object {
+				if len(args) != 2 {
+					return newError("instance_of butuhe 2 argumen")
+				}
+				instance, isInstance := args[0].(*object.Instance)
+				switch target := args[1].(type) {
+				case *object.Class:
+					if !isInstance {
+						return FALSE
+					}
+					for c := instance.Class; c != nil; c = c.Super {
+						if c == target {
+							return TRUE
+						}
+					}
+					return FALSE
+				case *object.Interface:
+					if !isInstance {
+						return FALSE
+					}
+					return nativeBoolToBooleanObject(satisfies(instance.Class, func(iface *object.Interface) bool {
+						return iface == target
+					}))
+				}
+				return newError("argumen kapindho instance_of kudu gerombolan utowo kontrak, oleh %s", args[1].Type())
+			},
+		},
+		"is_a": {
+			Fn: func(args ...object.Object) object.Object {
+				if len(args) != 2 {
+					return newError("is_a butuhe 2 argumen")
+				}
+				name, ok := args[1].(*object.String)
+				if !ok {
+					return newError("argumen kapindho is_a kudu jeneng tipe utowo gerombolan, oleh %s", args[1].Type())
+				}
+				return nativeBoolToBooleanObject(hasType(args[0], name.Value))
+			},
+		},
 		"moco_file": {
 			Fn: func(args ...object.Object) object.Object {
 				path := args[0].Inspect()
@@ -383,7 +599,7 @@ func init() {
 				}
 				content, err := os.ReadFile(path)
 				if err != nil {
-					return newError("gagal moco")
+					return newKindError("IOError", "gagal moco")
 				}
 				return &object.String{Value: string(content)}
 			},
@@ -417,7 +633,9 @@ func init() {
 					if r.Method == "POST" || r.Method == "PUT" || r.Method == "PATCH" {
 						contentType := r.Header.Get("Content-Type")
 						if strings.Contains(contentType, "application/json") {
-							json.NewDecoder(r.Body).Decode(&body)
+							dec := json.NewDecoder(r.Body)
+							dec.UseNumber()
+							dec.Decode(&body)
 						} else {
 							r.ParseForm()
 							for k, v := range r.PostForm {
@@ -435,9 +653,20 @@ func init() {
 					reqObj := convertToWolfObject(reqData)
 
 					res := applyFunction(handler, []object.Object{reqObj})
-					if res != nil {
-						fmt.Fprint(w, res.Inspect())
+					if res == nil {
+						return
+					}
+					// The body is written the way ketok prints, so __string is honoured
+					if !isError(res) {
+						res = stringify(res)
 					}
+					if err, ok := res.(*object.Error); ok {
+						// Uncaught errors are logged for the developer, not leaked to the client
+						fmt.Fprintf(os.Stderr, "%s %s\n%s\n", r.Method, r.URL.Path, FormatTraceback(err))
+						http.Error(w, "500 - Ana sing salah neng server", http.StatusInternalServerError)
+						return
+					}
+					fmt.Fprint(w, res.Inspect())
 				})
 				http.ListenAndServe(addr, nil)
 				return NULL
@@ -448,7 +677,7 @@ func init() {
 				path := args[0].Inspect()
 				db, err := sql.Open("sqlite", path)
 				if err != nil {
-					return newError("db error")
+					return newKindError("DbError", "db error: %s", err)
 				}
 				dbConnections["default"] = db
 				return &object.String{Value: "connected"}
@@ -458,18 +687,18 @@ func init() {
 			Fn: func(args ...object.Object) object.Object {
 				db := dbConnections["default"]
 				if db == nil {
-					return newError("no db")
+					return newKindError("DbError", "no db")
 				}
 				var params []interface{}
 				if len(args) > 1 {
 					arr := args[1].(*object.Array)
 					for _, el := range arr.Elements {
-						params = append(params, el.Inspect())
+						params = append(params, convertToNative(el))
 					}
 				}
 				rows, err := db.Query(args[0].Inspect(), params...)
 				if err != nil {
-					return newError("query error")
+					return newKindError("DbError", "query error: %s", err)
 				}
 				defer rows.Close()
 				cols, _ := rows.Columns()
@@ -496,18 +725,18 @@ func init() {
 			Fn: func(args ...object.Object) object.Object {
 				db := dbConnections["default"]
 				if db == nil {
-					return newError("no db")
+					return newKindError("DbError", "no db")
 				}
 				var params []interface{}
 				if len(args) > 1 {
 					arr := args[1].(*object.Array)
 					for _, el := range arr.Elements {
-						params = append(params, el.Inspect())
+						params = append(params, convertToNative(el))
 					}
 				}
 				_, err := db.Exec(args[0].Inspect(), params...)
 				if err != nil {
-					return newError("exec error")
+					return newKindError("DbError", "exec error: %s", err)
 				}
 				return TRUE
 			},
@@ -523,7 +752,20 @@ func init() {
 			},
 		},
 		"http_json": {
-			Fn: func(args ...object.Object) object.Object { return args[0] },
+			Fn: func(args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return newError("http_json butuhe 1 argumen")
+				}
+				val := jsonValue(args[0])
+				if isError(val) {
+					return val
+				}
+				encoded, err := json.Marshal(convertToNative(val))
+				if err != nil {
+					return newError("http_json gagal: %s", err)
+				}
+				return &object.String{Value: string(encoded)}
+			},
 		},
 		"http_error": {
 			Fn: func(args ...object.Object) object.Object {
@@ -561,3 +803,151 @@ func init() {
 		},
 	}
 }
+
+// templatePart is a block directive with its argument, or plain text when
+// directive is empty.
+type templatePart struct {
+	directive string
+	arg       string
+	text      string
+}
+
+// templateDirectives are the block directives, longer names first so
+// @yenora_yen is not read as @yenora.
+var templateDirectives = []struct {
+	name    string
+	hasArgs bool
+}{
+	{"@yenora_yen", true},
+	{"@yenora", false},
+	{"@yen", true},
+	{"@track_neng", true},
+	{"@punkyan_track", false},
+	{"@punkyan_yen", false},
+}
+
+// splitTemplateLine cuts line into text and block directives, so directives
+// work inline as well as on their own line. Surrounding whitespace is
+// dropped when the line holds just one directive.
+func splitTemplateLine(line string) ([]templatePart, object.Object) {
+	parts := []templatePart{}
+	text := 0
+	for i := 0; i < len(line); i++ {
+		// An @ inside a word, as in an email address, is just text
+		if line[i] != '@' || i > 0 && isTemplateNameChar(line[i-1]) {
+			continue
+		}
+		for _, d := range templateDirectives {
+			if !strings.HasPrefix(line[i:], d.name) {
+				continue
+			}
+			end := i + len(d.name)
+			if end < len(line) && isTemplateNameChar(line[end]) {
+				continue
+			}
+			part := templatePart{directive: d.name}
+			if d.hasArgs {
+				arg, next, ok := templateDirectiveArg(line, end)
+				if !ok {
+					return nil, newError("render_template: %s butuh (...) sing ditutup", d.name)
+				}
+				part.arg, end = arg, next
+			}
+			if i > text {
+				parts = append(parts, templatePart{text: line[text:i]})
+			}
+			parts = append(parts, part)
+			text = end
+			i = end - 1
+			break
+		}
+	}
+	if text < len(line) {
+		parts = append(parts, templatePart{text: line[text:]})
+	}
+
+	// A directive alone on its line takes the line's indentation with it
+	var directives []templatePart
+	for _, part := range parts {
+		if part.directive != "" {
+			directives = append(directives, part)
+		} else if strings.TrimSpace(part.text) != "" {
+			return parts, nil
+		}
+	}
+	if len(directives) == 1 {
+		return directives, nil
+	}
+	return parts, nil
+}
+
+// templateDirectiveArg reads the parenthesised argument starting at or after
+// pos, skipping brackets and quotes inside it, and returns it with the index
+// just past the closing parenthesis.
+func templateDirectiveArg(line string, pos int) (string, int, bool) {
+	for pos < len(line) && (line[pos] == ' ' || line[pos] == '\t') {
+		pos++
+	}
+	if pos >= len(line) || line[pos] != '(' {
+		return "", 0, false
+	}
+	depth := 0
+	var quote byte
+	for i := pos; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return strings.TrimSpace(line[pos+1 : i]), i + 1, true
+			}
+		}
+	}
+	return "", 0, false
+}
+
+func isTemplateNameChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+var templateOutputPattern = regexp.MustCompile(`{{\s*(.*?)\s*}}|{!!\s*(.*?)\s*!!}`)
+
+// compileTemplateLine turns a line of template text into a string expression,
+// escaping {{ }} output and passing {!! !!} output through untouched.
+func compileTemplateLine(line string) string {
+	parts := []string{}
+	last := 0
+	for _, m := range templateOutputPattern.FindAllStringSubmatchIndex(line, -1) {
+		if m[0] > last {
+			parts = append(parts, quoteTemplateText(line[last:m[0]]))
+		}
+		if m[2] >= 0 {
+			parts = append(parts, "html_escape("+line[m[2]:m[3]]+")")
+		} else {
+			parts = append(parts, "string("+line[m[4]:m[5]]+")")
+		}
+		last = m[1]
+	}
+	if last < len(line) || len(parts) == 0 {
+		parts = append(parts, quoteTemplateText(line[last:]))
+	}
+	return strings.Join(parts, " + ")
+}
+
+// templateTextEscaper keeps template text from being read as interpolation.
+var templateTextEscaper = strings.NewReplacer("$", "\\$", "{", "\\{")
+
+// quoteTemplateText turns literal template text into a string literal.
+func quoteTemplateText(text string) string {
+	return templateTextEscaper.Replace(strconv.Quote(text))
+}
@@ -2,17 +2,21 @@ package evaluator
 
 import (
 	"fmt"
+	"math"
 	"os"
+	"reflect"
+	"strings"
 	"wolf404/compiler/ast"
-	"wolf404/compiler/lexer"
 	"wolf404/compiler/object"
 	"wolf404/compiler/parser"
 )
 
 var (
-	NULL  = &object.Null{}
-	TRUE  = &object.Boolean{Value: true}
-	FALSE = &object.Boolean{Value: false}
+	NULL     = &object.Null{}
+	TRUE     = &object.Boolean{Value: true}
+	FALSE    = &object.Boolean{Value: false}
+	BREAK    = &object.Break{}
+	CONTINUE = &object.Continue{}
 )
 
 func Eval(node ast.Node, env *object.Environment) (result object.Object) {
@@ -20,6 +24,9 @@ func Eval(node ast.Node, env *object.Environment) (result object.Object) {
 		if r := recover(); r != nil {
 			result = newError("Kahanan darurat (Panic)! Interpreter mbrebes mileh: %v", r)
 		}
+		if err, ok := result.(*object.Error); ok {
+			claimErrorPosition(err, node)
+		}
 	}()
 
 	switch node := node.(type) {
@@ -31,14 +38,22 @@ func Eval(node ast.Node, env *object.Environment) (result object.Object) {
 		return Eval(node.Expression, env)
 
 	case *ast.LetStatement:
-		val := Eval(node.Value, env)
-		if isError(val) {
-			return val
+		var val object.Object = NULL
+		if node.Value != nil {
+			val = Eval(node.Value, env)
+			if isError(val) {
+				return val
+			}
 		}
-		// Variable names include '$' prefix in AST currently, but environment maps string keys directly.
 		env.Set(node.Name.Value, val)
 		return val
 
+	case *ast.GlobalStatement:
+		for _, name := range node.Names {
+			env.DeclareGlobal(name.Value)
+		}
+		return NULL
+
 	case *ast.ReturnStatement:
 		val := Eval(node.ReturnValue, env)
 		if isError(val) {
@@ -49,10 +64,25 @@ func Eval(node ast.Node, env *object.Environment) (result object.Object) {
 	case *ast.BlockStatement:
 		return evalBlockStatement(node, env)
 
+	case *ast.TryStatement:
+		return evalTryStatement(node, env)
+
+	case *ast.ThrowStatement:
+		return evalThrowStatement(node, env)
+
+	case *ast.BreakStatement:
+		return BREAK
+
+	case *ast.ContinueStatement:
+		return CONTINUE
+
 	case *ast.FunctionLiteral:
 		params := node.Parameters
 		body := node.Body
-		return &object.Function{Parameters: params, Env: env, Body: body}
+		return &object.Function{Name: node.Name, Parameters: params, Env: env, Body: body}
+
+	case *ast.NamedArgument:
+		return newError("argumen jeneng %s mung iso neng panggilan fungsi", node.Name)
 
 	case *ast.CallExpression:
 		if ident, ok := node.Function.(*ast.Identifier); ok && ident.Value == "nganggo" {
@@ -62,11 +92,11 @@ func Eval(node ast.Node, env *object.Environment) (result object.Object) {
 		if isError(function) {
 			return function
 		}
-		args := evalExpressions(node.Arguments, env)
-		if len(args) == 1 && isError(args[0]) {
-			return args[0]
+		args, named, err := evalCallArguments(node.Arguments, env)
+		if err != nil {
+			return err
 		}
-		return applyFunction(function, args)
+		return applyFunctionNamed(function, args, named)
 
 	case *ast.ProwlStatement:
 		// Execute in a goroutine!
@@ -86,6 +116,9 @@ func Eval(node ast.Node, env *object.Environment) (result object.Object) {
 	case *ast.IntegerLiteral:
 		return &object.Integer{Value: node.Value}
 
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
+
 	case *ast.ArrayLiteral:
 		elements := evalExpressions(node.Elements, env)
 		if len(elements) == 1 && isError(elements[0]) {
@@ -107,12 +140,28 @@ func Eval(node ast.Node, env *object.Environment) (result object.Object) {
 		}
 		return evalIndexExpression(left, index)
 
+	case *ast.SliceExpression:
+		return evalSliceExpression(node, env)
+
 	case *ast.Boolean:
 		return nativeBoolToBooleanObject(node.Value)
 
+	case *ast.NilLiteral:
+		return NULL
+
+	case *ast.PrefixExpression:
+		right := Eval(node.Right, env)
+		if isError(right) {
+			return right
+		}
+		return evalPrefixExpression(node.Operator, right)
+
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
 
+	case *ast.InterpolatedString:
+		return evalInterpolatedString(node, env)
+
 	case *ast.Identifier:
 		return evalIdentifier(node, env)
 
@@ -123,6 +172,12 @@ func Eval(node ast.Node, env *object.Environment) (result object.Object) {
 		if node.Operator == "=" {
 			return evalAssignmentExpression(node, env)
 		}
+		if operator, ok := compoundOperators[node.Operator]; ok {
+			return evalCompoundAssignment(node, operator, env)
+		}
+		if node.Operator == "and" || node.Operator == "or" {
+			return evalLogicalExpression(node, env)
+		}
 		left := Eval(node.Left, env)
 		if isError(left) {
 			return left
@@ -135,6 +190,30 @@ func Eval(node ast.Node, env *object.Environment) (result object.Object) {
 
 	case *ast.IfExpression:
 		return evalIfExpression(node, env)
+
+	case *ast.MatchExpression:
+		return evalMatchExpression(node, env)
+
+	case *ast.StaticStatement, *ast.ConstStatement:
+		return newError("%s mung iso neng njero gerombolan", node.TokenLiteral())
+
+	case *ast.UseStatement:
+		return newError("%s mung iso neng njero gerombolan utowo sifat", node.TokenLiteral())
+
+	case *ast.ExportStatement:
+		return Eval(node.Statement, env)
+
+	case *ast.InterfaceStatement:
+		return evalInterfaceStatement(node, env)
+
+	case *ast.TraitStatement:
+		return evalTraitStatement(node, env)
+
+	case *ast.SuperExpression:
+		if super, ok := env.Get("super"); ok {
+			return super
+		}
+		return newError("induk mung iso dienggo neng method gerombolan sing nduwe induk")
 	}
 
 	return newError("unknown node type: %T", node)
@@ -151,6 +230,8 @@ func evalProgram(program *ast.Program, env *object.Environment) object.Object {
 			return result.Value
 		case *object.Error:
 			return result
+		case *object.Break, *object.Continue:
+			return newError("'%s' kudu neng njero baleni", result.Inspect())
 		}
 	}
 
@@ -165,7 +246,8 @@ func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) obje
 
 		if result != nil {
 			rt := result.Type()
-			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ {
+			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ ||
+				rt == object.BREAK_OBJ || rt == object.CONTINUE_OBJ {
 				return result
 			}
 		}
@@ -190,7 +272,11 @@ func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Ob
 
 func applyHowl(args []object.Object) object.Object {
 	for _, arg := range args {
-		fmt.Println(arg.Inspect())
+		str := stringify(arg)
+		if isError(str) {
+			return str
+		}
+		fmt.Println(str.Inspect())
 	}
 	return NULL
 }
@@ -207,12 +293,68 @@ func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object
 	return newError("Lha, '%s' kok ora ono?", node.Value)
 }
 
+func evalPrefixExpression(operator string, right object.Object) object.Object {
+	switch operator {
+	case "not":
+		return nativeBoolToBooleanObject(!isTruthy(right))
+	case "-":
+		switch right := right.(type) {
+		case *object.Integer:
+			if right.Value == math.MinInt64 {
+				return &object.Float{Value: -float64(right.Value)}
+			}
+			return &object.Integer{Value: -right.Value}
+		case *object.Float:
+			return &object.Float{Value: -right.Value}
+		}
+		return newError("unknown operator: -%s", right.Type())
+	default:
+		return newError("unknown operator: %s%s", operator, right.Type())
+	}
+}
+
+// evalLogicalExpression short-circuits: the right side is only evaluated when
+// the left side does not already decide the result. The deciding operand is
+// returned as-is, so `$jeneng utowo "tamu"` works as a default value.
+func evalLogicalExpression(node *ast.InfixExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isError(left) {
+		return left
+	}
+
+	if node.Operator == "and" && !isTruthy(left) {
+		return left
+	}
+	if node.Operator == "or" && isTruthy(left) {
+		return left
+	}
+
+	return Eval(node.Right, env)
+}
+
 func evalInfixExpression(operator string, left, right object.Object) object.Object {
+	if result, ok := evalInstanceOperator(operator, left, right); ok {
+		return result
+	}
+
 	switch {
 	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
 		return evalIntegerInfixExpression(operator, left, right)
+	case isNumeric(left) && isNumeric(right):
+		return evalFloatInfixExpression(operator, left, right)
 	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
 		return evalStringInfixExpression(operator, left, right)
+	case operator == "*" && left.Type() == object.STRING_OBJ && right.Type() == object.INTEGER_OBJ:
+		return evalStringRepeat(left.(*object.String), right.(*object.Integer))
+	case operator == "*" && left.Type() == object.INTEGER_OBJ && right.Type() == object.STRING_OBJ:
+		return evalStringRepeat(right.(*object.String), left.(*object.Integer))
+	case operator == "+" && left.Type() == object.ARRAY_OBJ && right.Type() == object.ARRAY_OBJ:
+		leftElements := left.(*object.Array).Elements
+		rightElements := right.(*object.Array).Elements
+		elements := make([]object.Object, 0, len(leftElements)+len(rightElements))
+		elements = append(elements, leftElements...)
+		elements = append(elements, rightElements...)
+		return &object.Array{Elements: elements}
 	// Add relational operators for other types if needed
 	case operator == "==":
 		return nativeBoolToBooleanObject(left == right)
@@ -227,22 +369,92 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 	leftVal := left.(*object.Integer).Value
 	rightVal := right.(*object.Integer).Value
 
+	// Results past INTEGER range become FLOAT instead of wrapping around
 	switch operator {
 	case "+":
-		return &object.Integer{Value: leftVal + rightVal}
+		if result, ok := addInt64(leftVal, rightVal); ok {
+			return &object.Integer{Value: result}
+		}
+		return &object.Float{Value: float64(leftVal) + float64(rightVal)}
 	case "-":
-		return &object.Integer{Value: leftVal - rightVal}
+		if result, ok := subInt64(leftVal, rightVal); ok {
+			return &object.Integer{Value: result}
+		}
+		return &object.Float{Value: float64(leftVal) - float64(rightVal)}
 	case "*":
-		return &object.Integer{Value: leftVal * rightVal}
+		if result, ok := mulInt64(leftVal, rightVal); ok {
+			return &object.Integer{Value: result}
+		}
+		return &object.Float{Value: float64(leftVal) * float64(rightVal)}
 	case "/":
 		if rightVal == 0 {
-			return newError("Waduh, pembagian nol kui ora iso!")
+			return newKindError("ZeroDivisionError", "Waduh, pembagian nol kui ora iso!")
+		}
+		if leftVal == math.MinInt64 && rightVal == -1 {
+			return &object.Float{Value: -float64(leftVal)}
 		}
 		return &object.Integer{Value: leftVal / rightVal}
+	case "%":
+		if rightVal == 0 {
+			return newKindError("ZeroDivisionError", "Waduh, pembagian nol kui ora iso!")
+		}
+		return &object.Integer{Value: leftVal % rightVal}
+	case "**":
+		if rightVal < 0 {
+			return &object.Float{Value: math.Pow(float64(leftVal), float64(rightVal))}
+		}
+		if result, ok := intPow(leftVal, rightVal); ok {
+			return &object.Integer{Value: result}
+		}
+		return &object.Float{Value: math.Pow(float64(leftVal), float64(rightVal))}
 	case "<":
 		return nativeBoolToBooleanObject(leftVal < rightVal)
 	case ">":
 		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func evalFloatInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := toFloat(left)
+	rightVal := toFloat(right)
+
+	switch operator {
+	case "+":
+		return &object.Float{Value: leftVal + rightVal}
+	case "-":
+		return &object.Float{Value: leftVal - rightVal}
+	case "*":
+		return &object.Float{Value: leftVal * rightVal}
+	case "/":
+		if rightVal == 0 {
+			return newKindError("ZeroDivisionError", "Waduh, pembagian nol kui ora iso!")
+		}
+		return &object.Float{Value: leftVal / rightVal}
+	case "%":
+		if rightVal == 0 {
+			return newKindError("ZeroDivisionError", "Waduh, pembagian nol kui ora iso!")
+		}
+		return &object.Float{Value: math.Mod(leftVal, rightVal)}
+	case "**":
+		return &object.Float{Value: math.Pow(leftVal, rightVal)}
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
 	case "==":
 		return nativeBoolToBooleanObject(leftVal == rightVal)
 	case "!=":
@@ -259,6 +471,14 @@ func evalStringInfixExpression(operator string, left, right object.Object) objec
 	switch operator {
 	case "+":
 		return &object.String{Value: leftVal + rightVal}
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
 	case "==":
 		return nativeBoolToBooleanObject(leftVal == rightVal)
 	case "!=":
@@ -268,6 +488,75 @@ func evalStringInfixExpression(operator string, left, right object.Object) objec
 	}
 }
 
+// evalInterpolatedString joins the parts of "Halo {$jeneng}", converting
+// embedded values the same way the string builtin does.
+func evalInterpolatedString(node *ast.InterpolatedString, env *object.Environment) object.Object {
+	var out strings.Builder
+	for _, part := range node.Parts {
+		val := Eval(part, env)
+		if isError(val) {
+			return val
+		}
+		str := stringify(val)
+		if isError(str) {
+			return str
+		}
+		out.WriteString(str.Inspect())
+	}
+	return &object.String{Value: out.String()}
+}
+
+func evalStringRepeat(str *object.String, count *object.Integer) object.Object {
+	if count.Value < 0 {
+		return newError("string ora iso dibaleni %d kali", count.Value)
+	}
+	return &object.String{Value: strings.Repeat(str.Value, int(count.Value))}
+}
+
+// intPow computes base**exp for a non-negative exp by repeated squaring.
+// ok is false when the result does not fit in an int64.
+func intPow(base, exp int64) (result int64, ok bool) {
+	result = 1
+	for exp > 0 {
+		if exp&1 == 1 {
+			if result, ok = mulInt64(result, base); !ok {
+				return 0, false
+			}
+		}
+		exp >>= 1
+		if exp > 0 {
+			if base, ok = mulInt64(base, base); !ok {
+				return 0, false
+			}
+		}
+	}
+	return result, true
+}
+
+// addInt64 adds a and b, reporting false on overflow.
+func addInt64(a, b int64) (int64, bool) {
+	c := a + b
+	return c, (a^c)&(b^c) >= 0
+}
+
+// subInt64 subtracts b from a, reporting false on overflow.
+func subInt64(a, b int64) (int64, bool) {
+	c := a - b
+	return c, (a^b)&(a^c) >= 0
+}
+
+// mulInt64 multiplies a and b, reporting false on overflow.
+func mulInt64(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	c := a * b
+	if c/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, false
+	}
+	return c, true
+}
+
 func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
 	condition := Eval(ie.Condition, env)
 	if isError(condition) {
@@ -276,6 +565,8 @@ func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Obje
 
 	if isTruthy(condition) {
 		return Eval(ie.Consequence, env)
+	} else if ie.ElseIf != nil {
+		return evalIfExpression(ie.ElseIf, env)
 	} else if ie.Alternative != nil {
 		return Eval(ie.Alternative, env)
 	} else {
@@ -283,21 +574,41 @@ func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Obje
 	}
 }
 
+func isNumeric(obj object.Object) bool {
+	t := obj.Type()
+	return t == object.INTEGER_OBJ || t == object.FLOAT_OBJ
+}
+
+// toFloat widens an Integer or Float to float64 for mixed arithmetic.
+func toFloat(obj object.Object) float64 {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return float64(obj.Value)
+	case *object.Float:
+		return obj.Value
+	}
+	return 0
+}
+
 func isTruthy(obj object.Object) bool {
-	switch obj {
-	case NULL:
-		return false
-	case TRUE:
-		return true
-	case FALSE:
+	switch obj := obj.(type) {
+	case *object.Null:
 		return false
+	case *object.Boolean:
+		// Builtins allocate their own Booleans, so compare by value
+		return obj.Value
 	default:
 		return true
 	}
 }
 
 func newError(format string, a ...interface{}) *object.Error {
-	return &object.Error{Message: fmt.Sprintf(format, a...)}
+	return newKindError("Error", format, a...)
+}
+
+// newKindError creates an error whose kind can be matched in a cekel block.
+func newKindError(kind string, format string, a ...interface{}) *object.Error {
+	return &object.Error{Kind: kind, Message: fmt.Sprintf(format, a...)}
 }
 
 func isError(obj object.Object) bool {
@@ -314,34 +625,80 @@ func nativeBoolToBooleanObject(input bool) *object.Boolean {
 	return FALSE
 }
 
-func evalImport(node *ast.CallExpression, env *object.Environment) object.Object {
-	if len(node.Arguments) != 1 {
-		return newError("nganggo butuh 1 argumen (nama file)")
+// claimErrorPosition records node as the innermost position of err in the
+// frame currently being unwound. The first node to see an error wins, so
+// the position is the most specific one available.
+func claimErrorPosition(err *object.Error, node ast.Node) {
+	if err.FramePos != nil || isNilNode(node) {
+		return
 	}
-
-	arg := Eval(node.Arguments[0], env)
-	if isError(arg) {
-		return arg
+	tok := node.Pos()
+	if tok.Line == 0 {
+		return
+	}
+	pos := object.SourcePos{File: tok.File, Line: tok.Line, Column: tok.Column}
+	err.FramePos = &pos
+	if err.Pos.Line == 0 {
+		err.Pos = pos
 	}
+}
 
-	pathObj, ok := arg.(*object.String)
-	if !ok {
-		return newError("argumen nganggo kudu string")
+// isNilNode reports whether node is nil or a nil pointer, such as the
+// Alternative of an if without an else, which has no position to give.
+func isNilNode(node ast.Node) bool {
+	if node == nil {
+		return true
 	}
+	v := reflect.ValueOf(node)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
 
-	path := pathObj.Value
-	content, err := os.ReadFile(path)
-	if err != nil {
-		return newError("Gagal moco file %s: %s", path, err)
+// FormatTraceback renders err as a Python-style traceback, outermost call first.
+func FormatTraceback(err *object.Error) string {
+	frames := []object.StackFrame{}
+	if err.FramePos != nil {
+		frames = append(frames, object.StackFrame{Function: "<module>", Pos: *err.FramePos})
+	}
+	for i := len(err.Stack) - 1; i >= 0; i-- {
+		frames = append(frames, err.Stack[i])
 	}
 
-	l := lexer.New(string(content))
-	p := parser.New(l)
-	program := p.ParseProgram()
+	var out strings.Builder
+	if len(frames) > 0 {
+		out.WriteString("Traceback (most recent call last):\n")
+	}
+	for _, frame := range frames {
+		file := frame.Pos.File
+		if file == "" {
+			file = "<input>"
+		}
+		fmt.Fprintf(&out, "  File \"%s\", line %d, in %s\n", file, frame.Pos.Line, frame.Function)
+		if line := sourceLine(frame.Pos); line != "" {
+			fmt.Fprintf(&out, "    %s\n", line)
+		}
+	}
+	fmt.Fprintf(&out, "%s: %s", err.Kind, err.Message)
+	return out.String()
+}
 
-	if len(p.Errors()) != 0 {
-		return newError("Error parsing %s: %v", path, p.Errors())
+func sourceLine(pos object.SourcePos) string {
+	if pos.File == "" || pos.Line == 0 {
+		return ""
+	}
+	content, err := os.ReadFile(pos.File)
+	if err != nil {
+		return ""
+	}
+	lines := strings.Split(string(content), "\n")
+	if pos.Line > len(lines) {
+		return ""
 	}
+	return strings.TrimSpace(lines[pos.Line-1])
+}
 
-	return Eval(program, env)
+// printWarnings reports parser warnings for a loaded file on stderr.
+func printWarnings(p *parser.Parser, source string) {
+	if len(p.Warnings()) > 0 {
+		fmt.Fprint(os.Stderr, parser.FormatWarnings(p.Warnings(), source))
+	}
 }
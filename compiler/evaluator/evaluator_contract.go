@@ -0,0 +1,177 @@
+package evaluator
+
+import (
+	"sort"
+	"wolf404/compiler/ast"
+	"wolf404/compiler/object"
+)
+
+func evalInterfaceStatement(node *ast.InterfaceStatement, env *object.Environment) object.Object {
+	iface := &object.Interface{Name: node.Name.Value, Methods: node.Methods}
+
+	for _, name := range node.Parents {
+		parent, err := lookupInterface(name, env)
+		if err != nil {
+			return err
+		}
+		iface.Parents = append(iface.Parents, parent)
+	}
+
+	env.Set(node.Name.Value, iface)
+	return NULL
+}
+
+func evalTraitStatement(node *ast.TraitStatement, env *object.Environment) object.Object {
+	trait := &object.Trait{Name: node.Name.Value, Methods: make(map[string]*object.Function)}
+	var used []*object.Trait
+
+	for _, stmt := range node.Body.Statements {
+		switch stmt := stmt.(type) {
+		case *ast.UseStatement:
+			traits, err := lookupTraits(stmt, env)
+			if err != nil {
+				return err
+			}
+			used = append(used, traits...)
+
+		case *ast.ExpressionStatement:
+			funcLit := stmt.Expression.(*ast.FunctionLiteral)
+			fn, err := evalMethod(funcLit, env)
+			if err != nil {
+				return err
+			}
+			trait.Methods[funcLit.Name] = fn
+		}
+	}
+
+	// Methods of a sifat used by this one stay unowned until a class mixes
+	// them in
+	keep := func(fn *object.Function) *object.Function { return fn }
+	if err := mixTraits(trait.Name, trait.Methods, used, keep); err != nil {
+		return err
+	}
+
+	env.Set(node.Name.Value, trait)
+	return NULL
+}
+
+func lookupInterface(name *ast.Identifier, env *object.Environment) (*object.Interface, object.Object) {
+	obj, ok := env.Get(name.Value)
+	if !ok {
+		return nil, newError("kontrak ora ketemu: %s", name.Value)
+	}
+	iface, ok := obj.(*object.Interface)
+	if !ok {
+		return nil, newError("%s dudu kontrak, nanging %s", name.Value, obj.Type())
+	}
+	return iface, nil
+}
+
+func lookupTraits(stmt *ast.UseStatement, env *object.Environment) ([]*object.Trait, object.Object) {
+	traits := []*object.Trait{}
+	for _, name := range stmt.Traits {
+		obj, ok := env.Get(name.Value)
+		if !ok {
+			return nil, newError("sifat ora ketemu: %s", name.Value)
+		}
+		trait, ok := obj.(*object.Trait)
+		if !ok {
+			return nil, newError("%s dudu sifat, nanging %s", name.Value, obj.Type())
+		}
+		traits = append(traits, trait)
+	}
+	return traits, nil
+}
+
+// mixTraits copies the methods of traits into methods, passing each through
+// adopt first. Methods already in the table win over mixed-in ones; two
+// traits bringing different methods of the same name is an error unless
+// owner defines that method itself.
+func mixTraits(owner string, methods map[string]*object.Function, traits []*object.Trait, adopt func(*object.Function) *object.Function) object.Object {
+	own := make(map[string]bool, len(methods))
+	for name := range methods {
+		own[name] = true
+	}
+
+	origin := map[string]*object.Function{}
+	from := map[string]string{}
+	for _, trait := range traits {
+		names := make([]string, 0, len(trait.Methods))
+		for name := range trait.Methods {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			fn := trait.Methods[name]
+			if own[name] {
+				continue
+			}
+			if prev, ok := origin[name]; ok && prev != fn {
+				return newError("method %s ono neng sifat %s lan %s, tulisen dhewe neng %s", name, from[name], trait.Name, owner)
+			}
+			origin[name] = fn
+			from[name] = trait.Name
+			methods[name] = adopt(fn)
+		}
+	}
+	return nil
+}
+
+// checkContract reports the first method of iface, or of the kontrak it
+// extends, that class does not define with a compatible parameter list.
+func checkContract(class *object.Class, iface *object.Interface) object.Object {
+	for _, parent := range iface.Parents {
+		if err := checkContract(class, parent); err != nil {
+			return err
+		}
+	}
+
+	for _, sig := range iface.Methods {
+		method := findMethod(class, sig.Name)
+		if method == nil {
+			return newError("gerombolan %s ora netepi kontrak %s: method %s ora ono", class.Name, iface.Name, sig)
+		}
+		if !acceptsArgs(method.Parameters, len(sig.Parameters)) {
+			return newError("gerombolan %s ora netepi kontrak %s: method %s kudu iso nampa %d parameter", class.Name, iface.Name, sig.Name, len(sig.Parameters))
+		}
+	}
+	return nil
+}
+
+// acceptsArgs reports whether a function with params can be called with n
+// arguments.
+func acceptsArgs(params []*ast.Parameter, n int) bool {
+	required, max := 0, 0
+	for _, param := range params {
+		if param.Rest {
+			return required <= n
+		}
+		if param.Default == nil {
+			required++
+		}
+		max++
+	}
+	return required <= n && n <= max
+}
+
+// satisfies reports whether class or one of its parents netepi a kontrak
+// for which match is true, directly or through the kontrak's parents.
+func satisfies(class *object.Class, match func(*object.Interface) bool) bool {
+	var search func([]*object.Interface) bool
+	search = func(ifaces []*object.Interface) bool {
+		for _, iface := range ifaces {
+			if match(iface) || search(iface.Parents) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for c := class; c != nil; c = c.Super {
+		if search(c.Interfaces) {
+			return true
+		}
+	}
+	return false
+}
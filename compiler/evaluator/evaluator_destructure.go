@@ -0,0 +1,76 @@
+package evaluator
+
+import (
+	"strconv"
+	"wolf404/compiler/ast"
+	"wolf404/compiler/object"
+)
+
+// destructure unpacks val into the targets of an array or hash pattern,
+// recursing into nested patterns. bind stores each leaf: assignments go
+// through assignTo, while parameters use declare.
+// Hash pattern keys are evaluated in env.
+func destructure(pattern ast.Expression, val object.Object, env *object.Environment, bind func(ast.Expression, object.Object) object.Object) object.Object {
+	switch pattern := pattern.(type) {
+	case *ast.ArrayLiteral:
+		arr, ok := val.(*object.Array)
+		if !ok {
+			return newKindError("ValueError", "ora iso mbongkar %s dadi array", val.Type())
+		}
+		if len(arr.Elements) != len(pattern.Elements) {
+			return newKindError("ValueError", "mbongkar array butuh %d nilai, oleh %d", len(pattern.Elements), len(arr.Elements))
+		}
+		for i, target := range pattern.Elements {
+			if result := destructure(target, arr.Elements[i], env, bind); isError(result) {
+				return result
+			}
+		}
+		return val
+
+	case *ast.HashLiteral:
+		hash, ok := val.(*object.Hash)
+		if !ok {
+			return newKindError("ValueError", "ora iso mbongkar %s dadi hash", val.Type())
+		}
+		for _, keyNode := range pattern.Keys() {
+			key := Eval(keyNode, env)
+			if isError(key) {
+				return key
+			}
+			hashKey, ok := key.(object.Hashable)
+			if !ok {
+				return newError("unusable as hash key: %s", key.Type())
+			}
+			pair, ok := hash.Pairs[hashKey.HashKey()]
+			if !ok {
+				return newKindError("ValueError", "mbongkar hash butuh kunci %s, ora ono", describeKey(key))
+			}
+			if result := destructure(pattern.Pairs[keyNode], pair.Value, env, bind); isError(result) {
+				return result
+			}
+		}
+		return val
+	}
+
+	return bind(pattern, val)
+}
+
+// declare returns a binder that defines pattern variables directly in env,
+// as parameters do.
+func declare(env *object.Environment) func(ast.Expression, object.Object) object.Object {
+	return func(target ast.Expression, val object.Object) object.Object {
+		ident, ok := target.(*ast.Identifier)
+		if !ok {
+			return newError("pola mung oleh isi variabel, oleh %s", target)
+		}
+		env.Set(ident.Value, val)
+		return val
+	}
+}
+
+func describeKey(key object.Object) string {
+	if str, ok := key.(*object.String); ok {
+		return strconv.Quote(str.Value)
+	}
+	return key.Inspect()
+}
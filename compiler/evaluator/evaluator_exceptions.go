@@ -0,0 +1,116 @@
+package evaluator
+
+import (
+	"wolf404/compiler/ast"
+	"wolf404/compiler/object"
+)
+
+func evalTryStatement(node *ast.TryStatement, env *object.Environment) object.Object {
+	result := Eval(node.Body, env)
+
+	if err, ok := result.(*object.Error); ok && node.Catch != nil {
+		if node.CatchParam != nil {
+			err.Caught = errorToValue(err)
+			env.Assign(node.CatchParam.Value, err.Caught)
+			env.Assign(caughtName(node.CatchParam.Value), err)
+		}
+		result = Eval(node.Catch, env)
+	}
+
+	if node.Finally != nil {
+		// A return, error or loop signal from pungkasan wins over the earlier result
+		finallyResult := Eval(node.Finally, env)
+		if finallyResult != nil {
+			switch finallyResult.Type() {
+			case object.RETURN_VALUE_OBJ, object.ERROR_OBJ, object.BREAK_OBJ, object.CONTINUE_OBJ:
+				return finallyResult
+			}
+		}
+	}
+
+	if result == nil {
+		return NULL
+	}
+	return result
+}
+
+func evalThrowStatement(node *ast.ThrowStatement, env *object.Environment) object.Object {
+	val := Eval(node.Value, env)
+	if isError(val) {
+		return val
+	}
+
+	// uncalno $e inside cekel $e rethrows the caught error as it was,
+	// keeping its kind, position and stack
+	if ident, ok := node.Value.(*ast.Identifier); ok {
+		if caught, ok := env.Get(caughtName(ident.Value)); ok {
+			if err := caught.(*object.Error); err.Caught == val {
+				return err
+			}
+		}
+	}
+
+	err := &object.Error{Kind: "Error", Message: val.Inspect(), Value: val}
+
+	switch val := val.(type) {
+	case *object.Hash:
+		// uncalno {"kind": "HttpError", "message": "...", "code": 404}
+		if kind := hashGet(val, "kind"); kind != nil {
+			err.Kind = kind.Inspect()
+		}
+		if message := hashGet(val, "message"); message != nil {
+			err.Message = message.Inspect()
+		}
+	case *object.Instance:
+		err.Kind = val.Class.Name
+		if message, ok := val.Fields["message"]; ok {
+			err.Message = message.Inspect()
+		}
+	}
+
+	return err
+}
+
+// errorToValue turns an error into the value bound by `cekel $e`. Thrown
+// instances are handed back unchanged; everything else becomes a hash with
+// kind, message and stack (plus any extra keys of a thrown hash).
+func errorToValue(err *object.Error) object.Object {
+	if instance, ok := err.Value.(*object.Instance); ok {
+		return instance
+	}
+
+	hash := &object.Hash{Pairs: make(map[object.HashKey]object.HashPair)}
+	if thrown, ok := err.Value.(*object.Hash); ok {
+		for k, pair := range thrown.Pairs {
+			hash.Pairs[k] = pair
+		}
+	}
+
+	stack := make([]object.Object, len(err.Stack))
+	for i, frame := range err.Stack {
+		stack[i] = &object.String{Value: frame.Function + " (" + frame.Pos.String() + ")"}
+	}
+
+	hashSet(hash, "kind", &object.String{Value: err.Kind})
+	hashSet(hash, "message", &object.String{Value: err.Message})
+	hashSet(hash, "stack", &object.Array{Elements: stack})
+	return hash
+}
+
+// caughtName is where cekel keeps the error behind the value bound to name.
+// It can't clash with a variable, since identifiers never contain '#'.
+func caughtName(name string) string {
+	return name + "#cekel"
+}
+
+func hashGet(hash *object.Hash, key string) object.Object {
+	if pair, ok := hash.Pairs[(&object.String{Value: key}).HashKey()]; ok {
+		return pair.Value
+	}
+	return nil
+}
+
+func hashSet(hash *object.Hash, key string, val object.Object) {
+	k := &object.String{Value: key}
+	hash.Pairs[k.HashKey()] = object.HashPair{Key: k, Value: val}
+}
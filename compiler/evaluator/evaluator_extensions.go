@@ -31,10 +31,16 @@ func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Obje
 	return &object.Hash{Pairs: pairs}
 }
 
+// StrictIndexing makes out-of-range array and string indexing raise an
+// IndexError instead of returning kopong. `wlf gas --ketat` turns it on.
+var StrictIndexing = false
+
 func evalIndexExpression(left, index object.Object) object.Object {
 	switch {
 	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
 		return evalArrayIndexExpression(left, index)
+	case left.Type() == object.STRING_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalStringIndexExpression(left, index)
 	case left.Type() == object.HASH_OBJ:
 		return evalHashIndexExpression(left, index)
 	case left.Type() == object.INSTANCE_OBJ:
@@ -46,18 +52,158 @@ func evalIndexExpression(left, index object.Object) object.Object {
 
 func evalArrayIndexExpression(array, index object.Object) object.Object {
 	arrayObject := array.(*object.Array)
-	idx := index.(*object.Integer).Value
-	max := int64(len(arrayObject.Elements) - 1)
-
-	if idx < 0 || idx > max {
-		return NULL
+	idx, ok := resolveIndex(index.(*object.Integer).Value, len(arrayObject.Elements))
+	if !ok {
+		return outOfRange(index, len(arrayObject.Elements))
 	}
 
 	return arrayObject.Elements[idx]
 }
 
+// evalStringIndexExpression indexes by character, so "héllo"[1] is "é".
+func evalStringIndexExpression(str, index object.Object) object.Object {
+	runes := []rune(str.(*object.String).Value)
+	idx, ok := resolveIndex(index.(*object.Integer).Value, len(runes))
+	if !ok {
+		return outOfRange(index, len(runes))
+	}
+
+	return &object.String{Value: string(runes[idx])}
+}
+
+// resolveIndex maps a possibly negative index onto [0, length), counting
+// negative indices from the end.
+func resolveIndex(idx int64, length int) (int64, bool) {
+	if idx < 0 {
+		idx += int64(length)
+	}
+	return idx, idx >= 0 && idx < int64(length)
+}
+
+func outOfRange(index object.Object, length int) object.Object {
+	if StrictIndexing {
+		return newKindError("IndexError", "indeks %s ngluwihi dowo %d", index.Inspect(), length)
+	}
+	return NULL
+}
+
+func evalSliceExpression(node *ast.SliceExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isError(left) {
+		return left
+	}
+
+	bounds := make([]object.Object, 3)
+	for i, part := range []ast.Expression{node.Start, node.End, node.Step} {
+		if part == nil {
+			bounds[i] = NULL
+			continue
+		}
+		bounds[i] = Eval(part, env)
+		if isError(bounds[i]) {
+			return bounds[i]
+		}
+	}
+
+	switch left := left.(type) {
+	case *object.Array:
+		indices, err := sliceIndices(len(left.Elements), bounds[0], bounds[1], bounds[2])
+		if err != nil {
+			return err
+		}
+		elements := make([]object.Object, len(indices))
+		for i, idx := range indices {
+			elements[i] = left.Elements[idx]
+		}
+		return &object.Array{Elements: elements}
+
+	case *object.String:
+		runes := []rune(left.Value)
+		indices, err := sliceIndices(len(runes), bounds[0], bounds[1], bounds[2])
+		if err != nil {
+			return err
+		}
+		out := make([]rune, len(indices))
+		for i, idx := range indices {
+			out[i] = runes[idx]
+		}
+		return &object.String{Value: string(out)}
+	}
+
+	return newError("slice operator not supported: %s", left.Type())
+}
+
+// sliceIndices resolves [start:end:step] against a sequence of length items
+// the way Python does: bounds are clamped, so slicing never goes out of range.
+func sliceIndices(length int, startObj, endObj, stepObj object.Object) ([]int64, *object.Error) {
+	n := int64(length)
+
+	step, ok, err := sliceBound(stepObj, 1)
+	if err != nil {
+		return nil, err
+	}
+	if ok && step == 0 {
+		return nil, newError("step slice ora oleh 0")
+	}
+
+	// Negative steps walk backwards, so -1 means "before the first item"
+	lower, upper := int64(0), n
+	defaultStart, defaultEnd := int64(0), n
+	if step < 0 {
+		lower, upper = -1, n-1
+		defaultStart, defaultEnd = n-1, -1
+	}
+
+	clamp := func(obj object.Object, def int64) (int64, *object.Error) {
+		v, ok, err := sliceBound(obj, def)
+		if err != nil || !ok {
+			return v, err
+		}
+		if v < 0 {
+			v += n
+		}
+		if v < lower {
+			v = lower
+		}
+		if v > upper {
+			v = upper
+		}
+		return v, nil
+	}
+
+	start, err := clamp(startObj, defaultStart)
+	if err != nil {
+		return nil, err
+	}
+	end, err := clamp(endObj, defaultEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	indices := []int64{}
+	for i := start; (step > 0 && i < end) || (step < 0 && i > end); i += step {
+		indices = append(indices, i)
+	}
+	return indices, nil
+}
+
+// sliceBound reads one slice bound, returning def and false when it was omitted.
+func sliceBound(obj object.Object, def int64) (int64, bool, *object.Error) {
+	switch obj := obj.(type) {
+	case *object.Null:
+		return def, false, nil
+	case *object.Integer:
+		return obj.Value, true, nil
+	}
+	return 0, false, newError("wates slice kudu INTEGER, oleh %s", obj.Type())
+}
+
 func evalInstanceIndexExpression(instance, index object.Object) object.Object {
 	inst := instance.(*object.Instance)
+	if method := findMethod(inst.Class, "__index"); method != nil {
+		return applyMethod(method, []object.Object{index}, nil, inst)
+	}
+
 	key, ok := index.(*object.String)
 	if !ok {
 		return newError("index for instance must be string")
@@ -1,60 +1,268 @@
 package evaluator
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+	"wolf404/compiler/ast"
 	"wolf404/compiler/object"
 )
 
 // ... existing code ...
 
 func applyFunction(fn object.Object, args []object.Object) object.Object {
+	return applyFunctionNamed(fn, args, nil)
+}
+
+// applyFunctionNamed calls fn with positional args plus name: value args.
+func applyFunctionNamed(fn object.Object, args []object.Object, named map[string]object.Object) object.Object {
 	switch fn := fn.(type) {
 	case *object.Function:
-		extendedEnv := extendFunctionEnv(fn, args)
+		extendedEnv, err := extendFunctionEnv(fn, args, named, functionFrameName(fn))
+		if err != nil {
+			return err
+		}
 		evaluated := Eval(fn.Body, extendedEnv)
-		return unwrapReturnValue(evaluated)
+		return addStackFrame(unwrapReturnValue(evaluated), functionFrameName(fn))
 	case *object.Class:
 		instance := &object.Instance{Class: fn, Fields: make(map[string]object.Object)}
-		if init, ok := fn.Methods["init"]; ok {
+		// init is inherited like any other method
+		if init := findMethod(fn, "init"); init != nil {
 			// Call init with instance as $this
-			val := applyMethod(init, args, instance)
+			val := applyMethod(init, args, named, instance)
 			if isError(val) {
 				return val
 			}
+		} else if len(args) > 0 || len(named) > 0 {
+			return newKindError("ArgumentError", "%s ora nduwe init, ora iso nampa argumen", fn.Name)
 		}
 		return instance
 	case *object.BoundMethod:
-		return applyMethod(fn.Method, args, fn.Instance)
+		return applyMethod(fn.Method, args, named, fn.Instance)
+	case *object.ClassMethod:
+		return applyStaticMethod(fn.Method, args, named, fn.Class)
+	case *object.Super:
+		// induk(...) is short for induk.init(...)
+		if fn.Instance == nil {
+			return newError("induk(...) mung iso neng method instance")
+		}
+		if init := findMethod(fn.Class, "init"); init != nil {
+			return applyMethod(init, args, named, fn.Instance)
+		}
+		if len(args) > 0 || len(named) > 0 {
+			return newKindError("ArgumentError", "%s ora nduwe init, ora iso nampa argumen", fn.Class.Name)
+		}
+		return NULL
+	case *object.Instance:
+		if call := findMethod(fn.Class, "__call"); call != nil {
+			return applyMethod(call, args, named, fn)
+		}
+		return newError("Lha, %s iki dudu fungsi, ora nduwe method __call", fn.Class.Name)
 	case *object.Builtin:
+		if len(named) > 0 {
+			return newKindError("ArgumentError", "fungsi bawaan ora nampa argumen jeneng")
+		}
 		return fn.Fn(args...)
 	default:
 		return newError("Lha, %s iki dudu fungsi, ojo waton!", fn.Type())
 	}
 }
 
-func applyMethod(fn *object.Function, args []object.Object, instance *object.Instance) object.Object {
-	extendedEnv := extendFunctionEnv(fn, args)
-	extendedEnv.Set("this", instance)
+func applyMethod(fn *object.Function, args []object.Object, named map[string]object.Object, instance *object.Instance) object.Object {
+	class := instance.Class
+	if fn.Owner != nil {
+		class = fn.Owner
+	}
+	// induk resolves from the defining class, so it keeps working when the
+	// parent's method itself calls induk
+	var super *object.Super
+	if class.Super != nil {
+		super = &object.Super{Instance: instance, Class: class.Super}
+	}
+	return callMethod(fn, args, named, class, instance, super)
+}
+
+// applyStaticMethod calls a statis method with $this bound to receiver, the
+// class it was called through, so inherited static methods see the subclass.
+func applyStaticMethod(fn *object.Function, args []object.Object, named map[string]object.Object, receiver *object.Class) object.Object {
+	var super *object.Super
+	if fn.Owner.Super != nil {
+		super = &object.Super{Receiver: receiver, Class: fn.Owner.Super}
+	}
+	return callMethod(fn, args, named, fn.Owner, receiver, super)
+}
+
+func callMethod(fn *object.Function, args []object.Object, named map[string]object.Object, owner *object.Class, this object.Object, super *object.Super) object.Object {
+	frame := owner.Name + "." + functionFrameName(fn)
+	extendedEnv, err := extendFunctionEnv(fn, args, named, frame)
+	if err != nil {
+		return err
+	}
+	extendedEnv.Set("this", this)
+	if super != nil {
+		extendedEnv.Set("super", super)
+	}
 	evaluated := Eval(fn.Body, extendedEnv)
-	return unwrapReturnValue(evaluated)
+	return addStackFrame(unwrapReturnValue(evaluated), frame)
+}
+
+// addStackFrame records the function an error unwound through, at the
+// position the error reached inside it, and lets the caller claim the next one.
+func addStackFrame(obj object.Object, function string) object.Object {
+	if err, ok := obj.(*object.Error); ok {
+		frame := object.StackFrame{Function: function}
+		if err.FramePos != nil {
+			frame.Pos = *err.FramePos
+		}
+		err.Stack = append(err.Stack, frame)
+		err.FramePos = nil
+	}
+	return obj
 }
 
-func extendFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
+func functionFrameName(fn *object.Function) string {
+	if fn.Name == "" {
+		return "<garap>"
+	}
+	return fn.Name
+}
+
+// extendFunctionEnv binds args to fn's parameters: positional args first,
+// then named args, then defaults, which are evaluated at call time so they
+// can refer to earlier parameters. name is used in arity errors.
+func extendFunctionEnv(fn *object.Function, args []object.Object, named map[string]object.Object, name string) (*object.Environment, *object.Error) {
 	env := object.NewEnclosedEnvironment(fn.Env)
+	used := 0
+	missing := []string{}
+
+	for i, param := range fn.Parameters {
+		if param.Pattern != nil {
+			var value object.Object
+			switch {
+			case used < len(args):
+				value = args[used]
+				used++
+			case param.Default != nil:
+				value = Eval(param.Default, env)
+				if err, ok := value.(*object.Error); ok {
+					return nil, err
+				}
+			default:
+				missing = append(missing, fmt.Sprintf("parameter ke-%d", i+1))
+				continue
+			}
+			if err, ok := destructure(param.Pattern, value, env, declare(env)).(*object.Error); ok {
+				return nil, err
+			}
+			continue
+		}
 
-	for paramIdx, param := range fn.Parameters {
-		// fmt.Printf("DEBUG: Binding param '%s' to %s\n", param.Value, args[paramIdx].Inspect())
-		env.Set(param.Value, args[paramIdx])
+		paramName := param.Name.Value
+		if param.Rest {
+			rest := []object.Object{}
+			if used < len(args) {
+				rest = append(rest, args[used:]...)
+			}
+			env.Set(paramName, &object.Array{Elements: rest})
+			used = len(args)
+			continue
+		}
+
+		value, isNamed := named[paramName]
+		switch {
+		case used < len(args):
+			if isNamed {
+				return nil, newKindError("ArgumentError", "%s: argumen $%s diwenehi kaping pindho", name, paramName)
+			}
+			env.Set(paramName, args[used])
+			used++
+		case isNamed:
+			env.Set(paramName, value)
+		case param.Default != nil:
+			value := Eval(param.Default, env)
+			if err, ok := value.(*object.Error); ok {
+				return nil, err
+			}
+			env.Set(paramName, value)
+		default:
+			missing = append(missing, "$"+paramName)
+		}
+	}
+
+	if used < len(args) {
+		return nil, newKindError("ArgumentError", "%s: kakehan argumen, butuhe paling akeh %d, oleh %d", name, used, len(args))
+	}
+	for _, key := range sortedKeys(named) {
+		if !hasParameter(fn, key) {
+			return nil, newKindError("ArgumentError", "%s: ora nduwe parameter $%s", name, key)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, newKindError("ArgumentError", "%s: kurang argumen %s", name, strings.Join(missing, ", "))
+	}
+
+	return env, nil
+}
+
+func hasParameter(fn *object.Function, name string) bool {
+	for _, param := range fn.Parameters {
+		if param.Name != nil && param.Name.Value == name && !param.Rest {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]object.Object) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// evalCallArguments evaluates positional arguments in order and collects
+// name: value arguments separately.
+func evalCallArguments(exps []ast.Expression, env *object.Environment) ([]object.Object, map[string]object.Object, object.Object) {
+	args := []object.Object{}
+	var named map[string]object.Object
+
+	for _, exp := range exps {
+		if arg, ok := exp.(*ast.NamedArgument); ok {
+			if _, dup := named[arg.Name]; dup {
+				return nil, nil, newKindError("ArgumentError", "argumen %s diwenehi kaping pindho", arg.Name)
+			}
+			value := Eval(arg.Value, env)
+			if isError(value) {
+				return nil, nil, value
+			}
+			if named == nil {
+				named = make(map[string]object.Object)
+			}
+			named[arg.Name] = value
+			continue
+		}
+
+		value := Eval(exp, env)
+		if isError(value) {
+			return nil, nil, value
+		}
+		args = append(args, value)
 	}
 
-	return env
+	return args, named, nil
 }
 
 func unwrapReturnValue(obj object.Object) object.Object {
 	if obj == nil {
 		return NULL
 	}
-	if returnValue, ok := obj.(*object.ReturnValue); ok {
-		return returnValue.Value
+	switch obj := obj.(type) {
+	case *object.ReturnValue:
+		return obj.Value
+	case *object.Break, *object.Continue:
+		return newError("'%s' kudu neng njero baleni", obj.Inspect())
 	}
 	return obj
 }
@@ -1,28 +1,11 @@
 package evaluator
 
 import (
-	"os"
 	"wolf404/compiler/ast"
-	"wolf404/compiler/lexer"
 	"wolf404/compiler/object"
-	"wolf404/compiler/parser"
 )
 
 func evalClassStatement(node *ast.ClassStatement, env *object.Environment) object.Object {
-	methods := make(map[string]*object.Function)
-
-	for _, stmt := range node.Body.Statements {
-		if exprStmt, ok := stmt.(*ast.ExpressionStatement); ok {
-			if funcLit, ok := exprStmt.Expression.(*ast.FunctionLiteral); ok {
-				// It's a method definition
-				obj := Eval(funcLit, env)
-				if fn, ok := obj.(*object.Function); ok {
-					methods[funcLit.Name] = fn
-				}
-			}
-		}
-	}
-
 	var superClass *object.Class
 	if node.SuperClass != nil {
 		superObj, ok := env.Get(node.SuperClass.Value)
@@ -35,11 +18,107 @@ func evalClassStatement(node *ast.ClassStatement, env *object.Environment) objec
 		}
 	}
 
-	class := &object.Class{Name: node.Name.Value, Methods: methods, Super: superClass}
+	class := &object.Class{
+		Name:          node.Name.Value,
+		Super:         superClass,
+		Methods:       make(map[string]*object.Function),
+		StaticMethods: make(map[string]*object.Function),
+		Properties:    make(map[string]object.Object),
+		Constants:     make(map[string]object.Object),
+	}
+	// Members can refer to the class while the body runs, but env only
+	// gets it once every check below has passed
+	classEnv := object.NewEnclosedEnvironment(env)
+	classEnv.Set(node.Name.Value, class)
+	var traits []*object.Trait
+
+	for _, stmt := range node.Body.Statements {
+		switch stmt := stmt.(type) {
+		case *ast.ExpressionStatement:
+			if funcLit, ok := stmt.Expression.(*ast.FunctionLiteral); ok {
+				// It's a method definition
+				fn, err := evalMethod(funcLit, classEnv)
+				if err != nil {
+					return err
+				}
+				fn.Owner = class
+				class.Methods[funcLit.Name] = fn
+			}
+
+		case *ast.StaticStatement:
+			if stmt.Function != nil {
+				fn, err := evalMethod(stmt.Function, classEnv)
+				if err != nil {
+					return err
+				}
+				fn.Owner = class
+				class.StaticMethods[stmt.Function.Name] = fn
+				continue
+			}
+			var val object.Object = NULL
+			if stmt.Value != nil {
+				val = Eval(stmt.Value, classEnv)
+				if isError(val) {
+					return val
+				}
+			}
+			class.Properties[stmt.Name.Value] = val
+
+		case *ast.ConstStatement:
+			val := Eval(stmt.Value, classEnv)
+			if isError(val) {
+				return val
+			}
+			class.Constants[stmt.Name.Value] = val
+
+		case *ast.UseStatement:
+			used, err := lookupTraits(stmt, env)
+			if err != nil {
+				return err
+			}
+			traits = append(traits, used...)
+		}
+	}
+
+	// Trait methods become the class's own, so induk inside them reaches
+	// the parent of this class
+	adopt := func(fn *object.Function) *object.Function {
+		method := *fn
+		method.Owner = class
+		return &method
+	}
+	if err := mixTraits(class.Name, class.Methods, traits, adopt); err != nil {
+		return err
+	}
+
+	for _, name := range node.Interfaces {
+		iface, err := lookupInterface(name, env)
+		if err != nil {
+			return err
+		}
+		if err := checkContract(class, iface); err != nil {
+			return err
+		}
+		class.Interfaces = append(class.Interfaces, iface)
+	}
+
 	env.Set(node.Name.Value, class)
 	return NULL
 }
 
+// evalMethod evaluates the garap of a method or static method declaration.
+func evalMethod(funcLit *ast.FunctionLiteral, env *object.Environment) (*object.Function, object.Object) {
+	obj := Eval(funcLit, env)
+	if isError(obj) {
+		return nil, obj
+	}
+	fn, ok := obj.(*object.Function)
+	if !ok {
+		return nil, newError("method %s dudu garap, nanging %s", funcLit.Name, obj.Type())
+	}
+	return fn, nil
+}
+
 func findMethod(class *object.Class, name string) *object.Function {
 	if m, ok := class.Methods[name]; ok {
 		return m
@@ -50,6 +129,40 @@ func findMethod(class *object.Class, name string) *object.Function {
 	return nil
 }
 
+// classMember looks name up among the constants, static properties and
+// static methods of class and its parents. Static methods are bound to
+// receiver, the class they were reached through.
+func classMember(class, receiver *object.Class, name string) (object.Object, bool) {
+	for c := class; c != nil; c = c.Super {
+		if val, ok := c.Constants[name]; ok {
+			return val, true
+		}
+		if val, ok := c.Properties[name]; ok {
+			return val, true
+		}
+		if method, ok := c.StaticMethods[name]; ok {
+			return &object.ClassMethod{Method: method, Class: receiver}, true
+		}
+	}
+	return nil, false
+}
+
+// setClassProperty assigns a static property on the nearest class in the
+// chain that declares it, or on class itself when none does.
+func setClassProperty(class *object.Class, name string, val object.Object) object.Object {
+	for c := class; c != nil; c = c.Super {
+		if _, ok := c.Constants[name]; ok {
+			return newError("%s.%s iku konstanta, ora iso diganti", c.Name, name)
+		}
+		if _, ok := c.Properties[name]; ok {
+			c.Properties[name] = val
+			return val
+		}
+	}
+	class.Properties[name] = val
+	return val
+}
+
 func evalDotExpression(node *ast.InfixExpression, env *object.Environment) object.Object {
 	left := Eval(node.Left, env)
 	if isError(left) {
@@ -61,18 +174,54 @@ func evalDotExpression(node *ast.InfixExpression, env *object.Environment) objec
 		return newError("property access must be an identifier")
 	}
 
-	if instance, ok := left.(*object.Instance); ok {
+	switch left := left.(type) {
+	case *object.Instance:
 		// Field access
-		if val, ok := instance.Fields[rightIdent.Value]; ok {
+		if val, ok := left.Fields[rightIdent.Value]; ok {
 			return val
 		}
 
 		// Method lookup
-		if method := findMethod(instance.Class, rightIdent.Value); method != nil {
-			return &object.BoundMethod{Instance: instance, Method: method}
+		if method := findMethod(left.Class, rightIdent.Value); method != nil {
+			return &object.BoundMethod{Instance: left, Method: method}
+		}
+
+		// Class members are visible through instances too
+		if val, ok := classMember(left.Class, left.Class, rightIdent.Value); ok {
+			return val
 		}
 
 		return newError("property %s not found on INSTANCE", rightIdent.Value)
+
+	case *object.Class:
+		if val, ok := classMember(left, left, rightIdent.Value); ok {
+			return val
+		}
+		if findMethod(left, rightIdent.Value) != nil {
+			return newError("%s.%s dudu method statis, butuh instance", left.Name, rightIdent.Value)
+		}
+		return newError("gerombolan %s ora nduwe %s", left.Name, rightIdent.Value)
+
+	case *object.Super:
+		if left.Instance != nil {
+			if method := findMethod(left.Class, rightIdent.Value); method != nil {
+				return &object.BoundMethod{Instance: left.Instance, Method: method}
+			}
+		}
+		receiver := left.Receiver
+		if left.Instance != nil {
+			receiver = left.Instance.Class
+		}
+		if val, ok := classMember(left.Class, receiver, rightIdent.Value); ok {
+			return val
+		}
+		return newError("induk %s ora nduwe method %s", left.Class.Name, rightIdent.Value)
+
+	case *object.Module:
+		if val, ok := left.Export(rightIdent.Value); ok {
+			return val
+		}
+		return newError("modul %s ora ngekspor %s", left.Name, rightIdent.Value)
 	}
 
 	return newError("cannot access property of non-instance: %s", left.Type())
@@ -84,48 +233,191 @@ func evalAssignmentExpression(node *ast.InfixExpression, env *object.Environment
 		return val
 	}
 
+	return assignTo(node.Left, val, env)
+}
+
+// assignTo stores val in the place described by target. Containers on the
+// left of a property or index target are evaluated normally, so chained
+// targets like $this.config["db"]["path"] mutate the nested object in place.
+func assignTo(target ast.Expression, val object.Object, env *object.Environment) object.Object {
+	switch target := target.(type) {
 	// Case 1: Simple identifier variable assignment ($a = 1)
-	if leftIdent, ok := node.Left.(*ast.Identifier); ok {
-		env.Set(leftIdent.Value, val)
+	case *ast.Identifier:
+		// Name anonymous functions after their variable for tracebacks
+		if fn, ok := val.(*object.Function); ok && fn.Name == "" {
+			fn.Name = target.Value
+		}
+		env.Assign(target.Value, val)
 		return val
-	}
 
 	// Case 2: Property assignment (obj.prop = 1)
-	if leftInfix, ok := node.Left.(*ast.InfixExpression); ok && leftInfix.Operator == "." {
-		target := Eval(leftInfix.Left, env)
-		if isError(target) {
-			return target
+	case *ast.InfixExpression:
+		if target.Operator != "." {
+			break
+		}
+		container := Eval(target.Left, env)
+		if isError(container) {
+			return container
 		}
 
-		propNameIdent, ok := leftInfix.Right.(*ast.Identifier)
+		propNameIdent, ok := target.Right.(*ast.Identifier)
 		if !ok {
 			return newError("property name must be identifier")
 		}
 
-		if instance, ok := target.(*object.Instance); ok {
-			instance.Fields[propNameIdent.Value] = val
+		switch container := container.(type) {
+		case *object.Instance:
+			container.Fields[propNameIdent.Value] = val
 			return val
+		case *object.Class:
+			return setClassProperty(container, propNameIdent.Value, val)
+		}
+		return newError("cannot assign property to non-instance: %s", container.Type())
+
+	// Case 3: Index assignment ($arr[0] = 1, $hash["key"] = 1)
+	case *ast.IndexExpression:
+		container := Eval(target.Left, env)
+		if isError(container) {
+			return container
 		}
-		return newError("cannot assign property to non-instance: %s", target.Type())
+		index := Eval(target.Index, env)
+		if isError(index) {
+			return index
+		}
+		return assignIndex(container, index, val)
+
+	// Case 4: Destructuring ([$a, $b] = $pair, {"id": $id} = $row)
+	case *ast.ArrayLiteral, *ast.HashLiteral:
+		return destructure(target, val, env, func(leaf ast.Expression, v object.Object) object.Object {
+			return assignTo(leaf, v, env)
+		})
 	}
 
 	return newError("invalid assignment target")
 }
 
-func evalSummonStatement(node *ast.SummonStatement, env *object.Environment) object.Object {
-	path := node.Path.Value
-	content, err := os.ReadFile(path)
-	if err != nil {
-		return newError("gagal ngundang file: %s", err.Error())
+func assignIndex(container, index, val object.Object) object.Object {
+	switch container := container.(type) {
+	case *object.Array:
+		idx, ok := index.(*object.Integer)
+		if !ok {
+			return newError("array index must be INTEGER, got %s", index.Type())
+		}
+		pos, ok := resolveIndex(idx.Value, len(container.Elements))
+		if !ok {
+			return newKindError("IndexError", "array index %d out of range (dowo %d)", idx.Value, len(container.Elements))
+		}
+		container.Elements[pos] = val
+		return val
+
+	case *object.Hash:
+		key, ok := index.(object.Hashable)
+		if !ok {
+			return newError("unusable as hash key: %s", index.Type())
+		}
+		container.Pairs[key.HashKey()] = object.HashPair{Key: index, Value: val}
+		return val
+
+	case *object.Instance:
+		key, ok := index.(*object.String)
+		if !ok {
+			return newError("index for instance must be string")
+		}
+		container.Fields[key.Value] = val
+		return val
 	}
 
-	l := lexer.New(string(content))
-	p := parser.New(l)
-	program := p.ParseProgram()
+	return newError("index assignment not supported: %s", container.Type())
+}
+
+// compoundOperators maps each compound assignment to its underlying infix operator.
+var compoundOperators = map[string]string{
+	"+=": "+",
+	"-=": "-",
+	"*=": "*",
+	"/=": "/",
+	"%=": "%",
+}
+
+// evalCompoundAssignment handles $x += 1 and friends. The target's container
+// and index are evaluated once, so $a[f()] += 1 only calls f a single time.
+func evalCompoundAssignment(node *ast.InfixExpression, operator string, env *object.Environment) object.Object {
+	switch target := node.Left.(type) {
+	case *ast.Identifier:
+		current := evalIdentifier(target, env)
+		if isError(current) {
+			return current
+		}
+		val := evalCompoundValue(operator, current, node.Right, env)
+		if isError(val) {
+			return val
+		}
+		return assignTo(target, val, env)
 
-	if len(p.Errors()) != 0 {
-		return newError("gagal ngonversi file nggih diundang: %v", p.Errors())
+	case *ast.InfixExpression:
+		if target.Operator != "." {
+			break
+		}
+		container := Eval(target.Left, env)
+		if isError(container) {
+			return container
+		}
+		propNameIdent, ok := target.Right.(*ast.Identifier)
+		if !ok {
+			return newError("property name must be identifier")
+		}
+		switch container := container.(type) {
+		case *object.Instance:
+			current, ok := container.Fields[propNameIdent.Value]
+			if !ok {
+				return newError("property %s not found on INSTANCE", propNameIdent.Value)
+			}
+			val := evalCompoundValue(operator, current, node.Right, env)
+			if isError(val) {
+				return val
+			}
+			container.Fields[propNameIdent.Value] = val
+			return val
+		case *object.Class:
+			current, ok := classMember(container, container, propNameIdent.Value)
+			if !ok {
+				return newError("gerombolan %s ora nduwe %s", container.Name, propNameIdent.Value)
+			}
+			val := evalCompoundValue(operator, current, node.Right, env)
+			if isError(val) {
+				return val
+			}
+			return setClassProperty(container, propNameIdent.Value, val)
+		}
+		return newError("cannot assign property to non-instance: %s", container.Type())
+
+	case *ast.IndexExpression:
+		container := Eval(target.Left, env)
+		if isError(container) {
+			return container
+		}
+		index := Eval(target.Index, env)
+		if isError(index) {
+			return index
+		}
+		current := evalIndexExpression(container, index)
+		if isError(current) {
+			return current
+		}
+		val := evalCompoundValue(operator, current, node.Right, env)
+		if isError(val) {
+			return val
+		}
+		return assignIndex(container, index, val)
 	}
 
-	return Eval(program, env)
+	return newError("invalid assignment target")
+}
+
+func evalCompoundValue(operator string, current object.Object, rightNode ast.Expression, env *object.Environment) object.Object {
+	right := Eval(rightNode, env)
+	if isError(right) {
+		return right
+	}
+	return evalInfixExpression(operator, current, right)
 }
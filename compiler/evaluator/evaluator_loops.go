@@ -1,11 +1,16 @@
 package evaluator
 
 import (
+	"sort"
 	"wolf404/compiler/ast"
 	"wolf404/compiler/object"
 )
 
 func evalTrackStatement(ts *ast.TrackStatement, env *object.Environment) object.Object {
+	if ts.Iterable != nil {
+		return evalForInStatement(ts, env)
+	}
+
 	var result object.Object
 
 	const MAX_ITERATIONS = 1000000
@@ -31,6 +36,86 @@ func evalTrackStatement(ts *ast.TrackStatement, env *object.Environment) object.
 		// Handle return statements inside loop or errors
 		if result != nil {
 			rt := result.Type()
+			if rt == object.BREAK_OBJ {
+				return NULL
+			}
+			if rt == object.CONTINUE_OBJ {
+				// Don't let a terusno on the last pass escape the loop
+				result = NULL
+				continue
+			}
+			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ {
+				return result
+			}
+		}
+	}
+
+	return result
+}
+
+func evalForInStatement(ts *ast.TrackStatement, env *object.Environment) object.Object {
+	iterable := Eval(ts.Iterable, env)
+	if isError(iterable) {
+		return iterable
+	}
+
+	if instance, ok := iterable.(*object.Instance); ok {
+		iterable = iterValue(instance)
+		if isError(iterable) {
+			return iterable
+		}
+	}
+
+	var keys, values []object.Object
+
+	switch it := iterable.(type) {
+	case *object.Array:
+		values = it.Elements
+		for i := range it.Elements {
+			keys = append(keys, &object.Integer{Value: int64(i)})
+		}
+	case *object.Hash:
+		// A single loop variable receives the keys, like Python
+		for _, pair := range sortedPairs(it) {
+			keys = append(keys, pair.Key)
+			if ts.Key != nil {
+				values = append(values, pair.Value)
+			} else {
+				values = append(values, pair.Key)
+			}
+		}
+	case *object.String:
+		i := 0
+		for _, r := range it.Value {
+			keys = append(keys, &object.Integer{Value: int64(i)})
+			values = append(values, &object.String{Value: string(r)})
+			i++
+		}
+	default:
+		return newError("Ora iso dibaleni: %s", iterable.Type())
+	}
+
+	var result object.Object
+	for i, value := range values {
+		if ts.Key != nil {
+			env.Assign(ts.Key.Value, keys[i])
+		}
+		if bound := assignTo(ts.Value, value, env); isError(bound) {
+			return bound
+		}
+
+		result = Eval(ts.Body, env)
+
+		if result != nil {
+			rt := result.Type()
+			if rt == object.BREAK_OBJ {
+				return NULL
+			}
+			if rt == object.CONTINUE_OBJ {
+				// Don't let a terusno on the last pass escape the loop
+				result = NULL
+				continue
+			}
 			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ {
 				return result
 			}
@@ -39,3 +124,56 @@ func evalTrackStatement(ts *ast.TrackStatement, env *object.Environment) object.
 
 	return result
 }
+
+// sortedPairs returns hash pairs ordered by key so iteration is deterministic:
+// numbers first in numeric order, then strings, then salah before bener.
+func sortedPairs(hash *object.Hash) []object.HashPair {
+	pairs := make([]object.HashPair, 0, len(hash.Pairs))
+	for _, pair := range hash.Pairs {
+		pairs = append(pairs, pair)
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return keyLess(pairs[i].Key, pairs[j].Key)
+	})
+	return pairs
+}
+
+// keyRank groups hash key types in iteration order.
+func keyRank(key object.Object) int {
+	switch key.(type) {
+	case *object.Integer, *object.Float:
+		return 0
+	case *object.String:
+		return 1
+	case *object.Boolean:
+		return 2
+	}
+	return 3
+}
+
+func keyLess(a, b object.Object) bool {
+	if ra, rb := keyRank(a), keyRank(b); ra != rb {
+		return ra < rb
+	}
+	switch a := a.(type) {
+	case *object.Integer, *object.Float:
+		if x, ok := a.(*object.Integer); ok {
+			if y, ok := b.(*object.Integer); ok {
+				return x.Value < y.Value
+			}
+		}
+		x, y := toFloat(a), toFloat(b)
+		if x != y {
+			return x < y
+		}
+		// 1 and 1.0 are different keys; keep the INTEGER first
+		_, aInt := a.(*object.Integer)
+		_, bInt := b.(*object.Integer)
+		return aInt && !bInt
+	case *object.String:
+		return a.Value < b.(*object.String).Value
+	case *object.Boolean:
+		return !a.Value && b.(*object.Boolean).Value
+	}
+	return a.Inspect() < b.Inspect()
+}
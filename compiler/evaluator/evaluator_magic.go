@@ -0,0 +1,155 @@
+package evaluator
+
+import (
+	"strings"
+	"wolf404/compiler/object"
+)
+
+// operatorMethods maps infix operators to the magic methods an instance on
+// the left can define to overload them. != is the negation of __eq.
+var operatorMethods = map[string]string{
+	"+":  "__add",
+	"-":  "__sub",
+	"*":  "__mul",
+	"/":  "__div",
+	"%":  "__mod",
+	"==": "__eq",
+	"!=": "__eq",
+	"<":  "__lt",
+	">":  "__gt",
+	"<=": "__le",
+	">=": "__ge",
+}
+
+// magicMethod returns obj as an instance together with its method name,
+// inherited methods included, or nil when obj has no such method.
+func magicMethod(obj object.Object, name string) (*object.Instance, *object.Function) {
+	instance, ok := obj.(*object.Instance)
+	if !ok {
+		return nil, nil
+	}
+	method := findMethod(instance.Class, name)
+	if method == nil {
+		return nil, nil
+	}
+	return instance, method
+}
+
+// evalInstanceOperator applies an overloaded operator. ok is false when the
+// left operand does not overload operator, so the built-in rules apply.
+func evalInstanceOperator(operator string, left, right object.Object) (result object.Object, ok bool) {
+	name, found := operatorMethods[operator]
+	if !found {
+		return nil, false
+	}
+	instance, method := magicMethod(left, name)
+	if method == nil {
+		return nil, false
+	}
+
+	result = applyMethod(method, []object.Object{right}, nil, instance)
+	if operator == "!=" && !isError(result) {
+		return nativeBoolToBooleanObject(!isTruthy(result)), true
+	}
+	return result, true
+}
+
+// stringify converts obj the way the string builtin does, calling __string
+// on instances that define it, including those inside arrays and hashes.
+func stringify(obj object.Object) object.Object {
+	switch obj := obj.(type) {
+	case *object.Array:
+		elements := make([]string, len(obj.Elements))
+		for i, element := range obj.Elements {
+			str := stringify(element)
+			if isError(str) {
+				return str
+			}
+			elements[i] = str.Inspect()
+		}
+		return &object.String{Value: "[" + strings.Join(elements, ", ") + "]"}
+
+	case *object.Hash:
+		pairs := make([]string, 0, len(obj.Pairs))
+		for _, pair := range obj.Pairs {
+			value := stringify(pair.Value)
+			if isError(value) {
+				return value
+			}
+			pairs = append(pairs, pair.Key.Inspect()+": "+value.Inspect())
+		}
+		return &object.String{Value: "{" + strings.Join(pairs, ", ") + "}"}
+	}
+
+	instance, method := magicMethod(obj, "__string")
+	if method == nil {
+		return &object.String{Value: obj.Inspect()}
+	}
+
+	result := applyMethod(method, nil, nil, instance)
+	if isError(result) {
+		return result
+	}
+	if _, ok := result.(*object.String); !ok {
+		return newError("%s.__string kudu mbalekno STRING, oleh %s", instance.Class.Name, result.Type())
+	}
+	return result
+}
+
+// jsonValue replaces instances inside obj with what they encode as: the
+// result of __json, or a hash of their fields.
+func jsonValue(obj object.Object) object.Object {
+	switch obj := obj.(type) {
+	case *object.Instance:
+		if _, method := magicMethod(obj, "__json"); method != nil {
+			result := applyMethod(method, nil, nil, obj)
+			if isError(result) {
+				return result
+			}
+			return jsonValue(result)
+		}
+		fields := &object.Hash{Pairs: make(map[object.HashKey]object.HashPair, len(obj.Fields))}
+		for name, val := range obj.Fields {
+			key := &object.String{Value: name}
+			fields.Pairs[key.HashKey()] = object.HashPair{Key: key, Value: val}
+		}
+		return jsonValue(fields)
+
+	case *object.Array:
+		elements := make([]object.Object, len(obj.Elements))
+		for i, el := range obj.Elements {
+			elements[i] = jsonValue(el)
+			if isError(elements[i]) {
+				return elements[i]
+			}
+		}
+		return &object.Array{Elements: elements}
+
+	case *object.Hash:
+		pairs := make(map[object.HashKey]object.HashPair, len(obj.Pairs))
+		for hashKey, pair := range obj.Pairs {
+			val := jsonValue(pair.Value)
+			if isError(val) {
+				return val
+			}
+			pairs[hashKey] = object.HashPair{Key: pair.Key, Value: val}
+		}
+		return &object.Hash{Pairs: pairs}
+	}
+	return obj
+}
+
+// iterValue returns what a for-in loop walks for an instance: the result
+// of its __iter method.
+func iterValue(instance *object.Instance) object.Object {
+	method := findMethod(instance.Class, "__iter")
+	if method == nil {
+		return newError("Ora iso dibaleni: %s ora nduwe method __iter", instance.Class.Name)
+	}
+
+	result := applyMethod(method, nil, nil, instance)
+	if _, ok := result.(*object.Instance); ok {
+		return newError("%s.__iter kudu mbalekno ARRAY, HASH utowo STRING, oleh %s", instance.Class.Name, result.Type())
+	}
+	return result
+}
@@ -0,0 +1,152 @@
+package evaluator
+
+import (
+	"wolf404/compiler/ast"
+	"wolf404/compiler/object"
+)
+
+func evalMatchExpression(node *ast.MatchExpression, env *object.Environment) object.Object {
+	subject := Eval(node.Subject, env)
+	if isError(subject) {
+		return subject
+	}
+
+	for _, arm := range node.Arms {
+		for _, pattern := range arm.Patterns {
+			// The pattern and guard see the bindings in a scope of their own,
+			// so an arm that is not taken leaves the outer variables alone
+			armEnv := object.NewEnclosedEnvironment(env)
+			bindings := map[string]object.Object{}
+			matched, err := matchPattern(pattern, subject, armEnv, bindings)
+			if err != nil {
+				return err
+			}
+			if !matched {
+				continue
+			}
+
+			if arm.Guard != nil {
+				for name, value := range bindings {
+					armEnv.Set(name, value)
+				}
+				guard := Eval(arm.Guard, armEnv)
+				if isError(guard) {
+					return guard
+				}
+				if !isTruthy(guard) {
+					continue
+				}
+			}
+
+			// The taken arm's bindings stay visible afterwards, like loop variables
+			for name, value := range bindings {
+				env.Assign(name, value)
+			}
+			result := Eval(arm.Body, env)
+			if result == nil {
+				return NULL
+			}
+			return result
+		}
+	}
+
+	return newKindError("MatchError", "cocokno: ora ono pola sing cocok karo %s", subject.Inspect())
+}
+
+// matchPattern reports whether val fits pattern, collecting the variables
+// the pattern binds. Literal values and hash keys are evaluated in env.
+func matchPattern(pattern ast.Pattern, val object.Object, env *object.Environment, bindings map[string]object.Object) (bool, object.Object) {
+	switch pattern := pattern.(type) {
+	case *ast.WildcardPattern:
+		return true, nil
+
+	case *ast.BindingPattern:
+		bindings[pattern.Name.Value] = val
+		return true, nil
+
+	case *ast.LiteralPattern:
+		expected := Eval(pattern.Value, env)
+		if isError(expected) {
+			return false, expected
+		}
+		return isTruthy(evalInfixExpression("==", val, expected)), nil
+
+	case *ast.TypePattern:
+		if !hasType(val, pattern.TypeName) {
+			return false, nil
+		}
+		if pattern.Binding != nil {
+			bindings[pattern.Binding.Value] = val
+		}
+		return true, nil
+
+	case *ast.ArrayPattern:
+		arr, ok := val.(*object.Array)
+		if !ok {
+			return false, nil
+		}
+		if len(arr.Elements) < len(pattern.Elements) ||
+			(!pattern.HasRest && len(arr.Elements) != len(pattern.Elements)) {
+			return false, nil
+		}
+		for i, element := range pattern.Elements {
+			matched, err := matchPattern(element, arr.Elements[i], env, bindings)
+			if err != nil || !matched {
+				return false, err
+			}
+		}
+		if pattern.Rest != nil {
+			rest := append([]object.Object{}, arr.Elements[len(pattern.Elements):]...)
+			bindings[pattern.Rest.Value] = &object.Array{Elements: rest}
+		}
+		return true, nil
+
+	case *ast.HashPattern:
+		hash, ok := val.(*object.Hash)
+		if !ok {
+			return false, nil
+		}
+		for i, keyNode := range pattern.Keys {
+			key := Eval(keyNode, env)
+			if isError(key) {
+				return false, key
+			}
+			hashKey, ok := key.(object.Hashable)
+			if !ok {
+				return false, newError("unusable as hash key: %s", key.Type())
+			}
+			pair, ok := hash.Pairs[hashKey.HashKey()]
+			if !ok {
+				return false, nil
+			}
+			matched, err := matchPattern(pattern.Values[i], pair.Value, env, bindings)
+			if err != nil || !matched {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+
+	return false, newError("unknown pattern: %T", pattern)
+}
+
+// hasType reports whether val is of the named type (STRING, HASH, ...) or an
+// instance of the named class, one of its subclasses, or a class that
+// netepi the named kontrak.
+func hasType(val object.Object, name string) bool {
+	if string(val.Type()) == name {
+		return true
+	}
+	instance, ok := val.(*object.Instance)
+	if !ok {
+		return false
+	}
+	for class := instance.Class; class != nil; class = class.Super {
+		if class.Name == name {
+			return true
+		}
+	}
+	return satisfies(instance.Class, func(iface *object.Interface) bool {
+		return iface.Name == name
+	})
+}
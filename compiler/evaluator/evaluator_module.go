@@ -0,0 +1,291 @@
+package evaluator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"wolf404/compiler/ast"
+	"wolf404/compiler/lexer"
+	"wolf404/compiler/object"
+	"wolf404/compiler/parser"
+)
+
+// ModulePaths lists where nganggo and undang look for a file, in order: the
+// project root, installed packs, then the standard library.
+var ModulePaths = []string{".", "packs", stdlibDir()}
+
+var (
+	// moduleMu guards modules and the entries in it. It is not held while
+	// a module runs, since that module may import others.
+	moduleMu sync.Mutex
+
+	// modules tracks every module that is loading or loaded by absolute
+	// path, so a file runs once no matter how many files, or goroutines,
+	// import it.
+	modules = map[string]*moduleEntry{}
+)
+
+// moduleEntry is a module that is loading or has loaded. done is closed once
+// it has run, after module or err is set.
+type moduleEntry struct {
+	module *object.Module
+	err    object.Object
+	done   chan struct{}
+
+	// waiting is the entry this module's import is waiting for while
+	// another goroutine loads it
+	waiting *moduleEntry
+}
+
+func (e *moduleEntry) finished() bool {
+	select {
+	case <-e.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// moduleKey binds a module's own object in its environment, so an import can
+// tell which module it runs in. It can't clash with a Wolf404 name.
+const moduleKey = "#modul"
+
+// stdlibDir is $WOLF404_PATH, or the stdlib directory next to the wlf
+// executable.
+func stdlibDir() string {
+	if dir := os.Getenv("WOLF404_PATH"); dir != "" {
+		return dir
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return "stdlib"
+	}
+	return filepath.Join(filepath.Dir(exe), "stdlib")
+}
+
+// resolveModule finds path in ModulePaths, trying path.wlf when path has no
+// extension, and returns its absolute path.
+func resolveModule(path string) (string, bool) {
+	candidates := []string{path}
+	if filepath.Ext(path) == "" {
+		candidates = append(candidates, path+".wlf")
+	}
+
+	dirs := ModulePaths
+	if filepath.IsAbs(path) {
+		dirs = []string{""}
+	}
+	for _, dir := range dirs {
+		for _, candidate := range candidates {
+			full := filepath.Join(dir, candidate)
+			if info, err := os.Stat(full); err == nil && !info.IsDir() {
+				abs, err := filepath.Abs(full)
+				if err != nil {
+					return "", false
+				}
+				return abs, true
+			}
+		}
+	}
+	return "", false
+}
+
+// loadModule returns the module for path, evaluating it in a fresh
+// environment the first time. env is where the import runs, used to follow
+// the chain of modules still loading and report cycles. Imports of a file
+// another goroutine is loading wait for it to finish.
+func loadModule(path string, env *object.Environment) (*object.Module, object.Object) {
+	abs, ok := resolveModule(path)
+	if !ok {
+		return nil, newKindError("ImportError", "modul %s ora ketemu neng %s", path, strings.Join(ModulePaths, ", "))
+	}
+
+	importer := loadingModule(env)
+	for m := importer; m != nil; m = m.Importer {
+		if m.Path == abs {
+			return nil, importCycle(importer, m, path)
+		}
+	}
+
+	moduleMu.Lock()
+	entry, started := modules[abs]
+	if !started {
+		entry = &moduleEntry{done: make(chan struct{})}
+		modules[abs] = entry
+	}
+	moduleMu.Unlock()
+	if started {
+		return awaitModule(entry, importer, path)
+	}
+
+	module, err := evalModule(path, abs, importer)
+	moduleMu.Lock()
+	entry.module, entry.err = module, err
+	// A failed import is not kept, so a later one tries again
+	if err != nil {
+		delete(modules, abs)
+	}
+	moduleMu.Unlock()
+	close(entry.done)
+	return module, err
+}
+
+// awaitModule waits for the goroutine loading entry to finish. When the
+// imports entry waits for lead back to a module in importer's chain, both
+// sides would wait forever, so that is reported as a cycle instead.
+func awaitModule(entry *moduleEntry, importer *object.Module, path string) (*object.Module, object.Object) {
+	moduleMu.Lock()
+	var self *moduleEntry
+	if importer != nil {
+		self = modules[importer.Path]
+		for e := entry; e != nil; e = e.waiting {
+			for m := importer; m != nil; m = m.Importer {
+				if modules[m.Path] == e {
+					moduleMu.Unlock()
+					return nil, importCycle(importer, nil, path)
+				}
+			}
+		}
+	}
+	if self != nil {
+		self.waiting = entry
+	}
+	moduleMu.Unlock()
+
+	<-entry.done
+
+	if self != nil {
+		moduleMu.Lock()
+		self.waiting = nil
+		moduleMu.Unlock()
+	}
+	return entry.module, entry.err
+}
+
+// importCycle reports importing path from importer, going back up the
+// chain of importers to from, or to the entry file when from is nil.
+func importCycle(importer, from *object.Module, path string) object.Object {
+	chain := []string{path}
+	for c := importer; c != nil && c != from; c = c.Importer {
+		chain = append([]string{c.Name}, chain...)
+	}
+	if from != nil {
+		chain = append([]string{from.Name}, chain...)
+	}
+	return newKindError("ImportError", "modul muter: %s", strings.Join(chain, " -> "))
+}
+
+// evalModule reads, parses and runs the file at abs.
+func evalModule(path, abs string, importer *object.Module) (*object.Module, object.Object) {
+	content, err := os.ReadFile(abs)
+	if err != nil {
+		return nil, newKindError("ImportError", "Gagal moco file %s: %s", path, err)
+	}
+
+	l := lexer.NewWithFile(string(content), path)
+	p := parser.New(l)
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		return nil, newError("Error parsing %s: %v", path, p.Errors())
+	}
+	printWarnings(p, string(content))
+
+	module := &object.Module{Name: path, Path: abs, Env: object.NewEnvironment(), Importer: importer}
+	module.Env.Set(moduleKey, module)
+	for _, stmt := range program.Statements {
+		if export, ok := stmt.(*ast.ExportStatement); ok {
+			module.Exports = append(module.Exports, export.Name)
+		}
+	}
+
+	for _, stmt := range program.Statements {
+		result := Eval(stmt, module.Env)
+
+		switch result := result.(type) {
+		case *object.ReturnValue:
+			module.Value = result.Value
+		case *object.Error:
+			return nil, addStackFrame(result, "<module>")
+		case *object.Break, *object.Continue:
+			return nil, newError("'%s' kudu neng njero baleni", result.Inspect())
+		default:
+			continue
+		}
+		break
+	}
+	return module, nil
+}
+
+// loadingModule returns the module env belongs to while that module is
+// still being evaluated, and nil otherwise. Imports from the entry file or
+// from a module that finished loading start a new chain.
+func loadingModule(env *object.Environment) *object.Module {
+	obj, ok := env.Get(moduleKey)
+	if !ok {
+		return nil
+	}
+	module := obj.(*object.Module)
+	moduleMu.Lock()
+	defer moduleMu.Unlock()
+	if entry, ok := modules[module.Path]; ok && entry.finished() && entry.module == module {
+		return nil
+	}
+	return module
+}
+
+// bindExports defines every export of module in env.
+func bindExports(module *object.Module, env *object.Environment) {
+	for _, name := range module.Exports {
+		if val, ok := module.Env.Get(name); ok {
+			env.Set(name, val)
+		}
+	}
+}
+
+// evalImport handles nganggo("path"): the module's exports are bound in env,
+// and the call returns what the module's top-level balekno returned, or the
+// module itself.
+func evalImport(node *ast.CallExpression, env *object.Environment) object.Object {
+	if len(node.Arguments) != 1 {
+		return newError("nganggo butuh 1 argumen (nama file)")
+	}
+
+	arg := Eval(node.Arguments[0], env)
+	if isError(arg) {
+		return arg
+	}
+
+	pathObj, ok := arg.(*object.String)
+	if !ok {
+		return newError("argumen nganggo kudu string")
+	}
+
+	module, err := loadModule(pathObj.Value, env)
+	if err != nil {
+		return err
+	}
+	bindExports(module, env)
+
+	if module.Value != nil {
+		return module.Value
+	}
+	return module
+}
+
+// evalSummonStatement handles undang "path", which binds the exports, and
+// undang "path" dadi Alias, which binds only the module under Alias.
+func evalSummonStatement(node *ast.SummonStatement, env *object.Environment) object.Object {
+	module, err := loadModule(node.Path.Value, env)
+	if err != nil {
+		return err
+	}
+
+	if node.Alias != nil {
+		env.Set(node.Alias.Value, module)
+	} else {
+		bindExports(module, env)
+	}
+	return NULL
+}
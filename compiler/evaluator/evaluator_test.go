@@ -0,0 +1,1094 @@
+package evaluator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"wolf404/compiler/ast"
+	"wolf404/compiler/lexer"
+	"wolf404/compiler/object"
+	"wolf404/compiler/parser"
+)
+
+// evalTest is a program and what its last statement evaluates to: the
+// value's Inspect(), or "Kind: message" for an error.
+type evalTest struct {
+	input    string
+	expected string
+}
+
+func testEval(t *testing.T, input string) object.Object {
+	t.Helper()
+	p := parser.New(lexer.NewWithFile(input, "test.wlf"))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parse errors for %q: %v", input, p.Errors())
+	}
+	return Eval(program, object.NewEnvironment())
+}
+
+func describe(obj object.Object) string {
+	if obj == nil {
+		return "<nil>"
+	}
+	if err, ok := obj.(*object.Error); ok {
+		return err.Kind + ": " + err.Message
+	}
+	return obj.Inspect()
+}
+
+func runEvalTests(t *testing.T, tests []evalTest) {
+	t.Helper()
+	for _, tt := range tests {
+		if got := describe(testEval(t, tt.input)); got != tt.expected {
+			t.Errorf("%q\n got: %s\nwant: %s", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestFloatArithmetic(t *testing.T) {
+	runEvalTests(t, []evalTest{
+		{"1.5", "1.5"},
+		{"1.5 + 2", "3.5"},
+		{"2 * 0.25", "0.5"},
+		{"7 / 2", "3"},
+		{"7.0 / 2", "3.5"},
+		{"-2.5", "-2.5"},
+		{"1.0 == 1", "true"},
+		{"0.1 < 0.2", "true"},
+	})
+}
+
+func TestIntegerPower(t *testing.T) {
+	runEvalTests(t, []evalTest{
+		{"2 ** 10", "1024"},
+		{"2 ** 62", "4611686018427387904"},
+		{"(-2) ** 63", "-9223372036854775808"},
+		{"2 ** 0", "1"},
+		{"2 ** -1", "0.5"},
+		// Overflow switches to FLOAT instead of wrapping negative
+		{"2 ** 63", "9223372036854776000.0"},
+		{"3 ** 40", "12157665459056929000.0"},
+		{"2.0 ** 3", "8.0"},
+	})
+}
+
+func TestIntegerOverflow(t *testing.T) {
+	// Every integer operator promotes to FLOAT past INTEGER range
+	runEvalTests(t, []evalTest{
+		{"9223372036854775807 + 0", "9223372036854775807"},
+		{"9223372036854775807 + 1", "9223372036854776000.0"},
+		{"-9223372036854775807 - 1", "-9223372036854775808"},
+		{"-9223372036854775807 - 2", "-9223372036854776000.0"},
+		{"9223372036854775807 - -1", "9223372036854776000.0"},
+		{"4611686018427387904 * 2", "9223372036854776000.0"},
+		{"-4611686018427387904 * 2", "-9223372036854775808"},
+		{"3037000500 * 3037000500", "9223372037000250000.0"},
+		{"(-9223372036854775807 - 1) / -1", "9223372036854776000.0"},
+		{"-(-9223372036854775807 - 1)", "9223372036854776000.0"},
+		{"$x = 9223372036854775807\n$x += 1\n$x", "9223372036854776000.0"},
+	})
+}
+
+func TestPrefixAndLogicalOperators(t *testing.T) {
+	runEvalTests(t, []evalTest{
+		{"-5", "-5"},
+		{"-(-5)", "5"},
+		{"ora bener", "false"},
+		{"not kopong", "true"},
+		{"1 > 0 lan 2 > 0", "true"},
+		{"1 > 0 and 2 < 0", "false"},
+		{"salah utowo 3", "3"},
+		{`$jeneng = kopong
+$jeneng utowo "tamu"`, "tamu"},
+		// The right side is never evaluated once the left decides
+		{"salah lan $ora_ono", "false"},
+		{"bener utowo $ora_ono", "true"},
+		{"ora 1 == 2", "true"},
+	})
+}
+
+func TestComparisonAndArithmeticOperators(t *testing.T) {
+	runEvalTests(t, []evalTest{
+		{"3 <= 3", "true"},
+		{"2 >= 3", "false"},
+		{"10 % 3", "1"},
+		{"5.5 % 2", "1.5"},
+		{"1 / 0", "ZeroDivisionError: Waduh, pembagian nol kui ora iso!"},
+		{"10 % 0", "ZeroDivisionError: Waduh, pembagian nol kui ora iso!"},
+		{`"abc" < "abd"`, "true"},
+		{`"b" >= "a"`, "true"},
+		{`"ab" * 3`, "ababab"},
+		{`2 * "-"`, "--"},
+		{"[1] + [2, 3]", "[1, 2, 3]"},
+		{`"a" - "b"`, "Error: unknown operator: STRING - STRING"},
+	})
+}
+
+func TestForInLoops(t *testing.T) {
+	runEvalTests(t, []evalTest{
+		{`$sum = 0
+baleni $x neng [1, 2, 3]
+    $sum += $x
+$sum`, "6"},
+		{`$keys = ""
+baleni $k, $v neng {"a": 1, "b": 2}
+    $keys = "{$keys}{$k}{$v}"
+$keys`, "a1b2"},
+		{`$out = ""
+baleni $i, $c neng "abc"
+    $out = "{$out}{$i}{$c}"
+$out`, "0a1b2c"},
+		{`$sum = 0
+baleni $i neng deret(0, 10, 2)
+    $sum += $i
+$sum`, "20"},
+		{`$n = 0
+baleni $i neng deret(3)
+    $n += 1
+$n`, "3"},
+		{`$out = ""
+baleni $i neng deret(3, 0, -1)
+    $out = "{$out}{$i}"
+$out`, "321"},
+		// Hash keys come numbers first in numeric order, then strings, then booleans
+		{`$keys = ""
+baleni $k, $v neng {"b": 1, 10: 1, bener: 1, 2: 1, "a": 1, 1.5: 1, salah: 1}
+    $keys = "{$keys}{$k} "
+$keys`, "1.5 2 10 a b false true "},
+		{"deret(1000000000000)", "Error: deret kegedhen: 1000000000000 elemen, paling akeh 10000000"},
+		{"deret(9223372036854775800, 9223372036854775807, 5)", "[9223372036854775800, 9223372036854775805]"},
+		{"deret(5, 0)", "[]"},
+		{`baleni $x neng 5
+    $x`, "Error: Ora iso dibaleni: INTEGER"},
+		// The while form still works
+		{`$i = 0
+track $i < 3
+    $i += 1
+$i`, "3"},
+	})
+}
+
+func TestTemplateTrackNeng(t *testing.T) {
+	runEvalTests(t, []evalTest{
+		{`render_template('@track_neng($x neng $items)\n<li>{{ $x }}</li>\n@punkyan_track', {"items": [1, 2]})`, "<li>1</li>\n<li>2</li>\n"},
+		{`render_template('@track_neng($k, $v neng $h)\n{{ $k }}={{ $v }}\n@punkyan_track', {"h": {"a": 1}})`, "a=1\n"},
+		{`render_template('@track_neng($x neng $items)', {"items": []})`, "Error: render_template: @yen/@track_neng ora ditutup"},
+	})
+}
+
+func TestTemplateUsesStringMethod(t *testing.T) {
+	money := "gerombolan Money\n    garap init($amount)\n        $this.amount = $amount\n    garap __string()\n        balekno \"<Rp {$this.amount}>\"\n"
+	runEvalTests(t, []evalTest{
+		{money + `render_template('{{ $m }}', {"m": Money(5)})`, "&lt;Rp 5&gt;\n"},
+		{money + `render_template('{!! $m !!}', {"m": Money(5)})`, "<Rp 5>\n"},
+		{money + `render_template('{{ $ms }}', {"ms": [Money(1), "<b>"]})`, "[&lt;Rp 1&gt;, &lt;b&gt;]\n"},
+		{money + `html_escape(Money(7))`, "&lt;Rp 7&gt;"},
+	})
+}
+
+func TestTemplateInlineDirectives(t *testing.T) {
+	runEvalTests(t, []evalTest{
+		{`render_template('<ul>@track_neng($x neng $xs)<li>{{ $x }}</li>@punkyan_track</ul>', {"xs": [1, 2]})`, "<ul><li>1</li><li>2</li></ul>\n"},
+		{`render_template('<b>@yen($a)on @yenora off @punkyan_yen</b>', {"a": bener})`, "<b>on </b>\n"},
+		{`render_template('<b>@yen($a)on @yenora off @punkyan_yen</b>', {"a": salah})`, "<b> off </b>\n"},
+		{`render_template('<b>@yen($a)@punkyan_yen</b>', {"a": bener})`, "<b></b>\n"},
+		// Arguments may hold brackets and quoted parentheses
+		{`render_template('@yen(dowo($s) > 1 lan $s != ")")\nok\n@punkyan_yen', {"s": "ab"})`, "ok\n"},
+		// A lone, indented directive still drops its whole line
+		{`render_template('<ul>\n    @track_neng($x neng $xs)\n    <li>{{ $x }}</li>\n    @punkyan_track\n</ul>', {"xs": [1]})`, "<ul>\n    <li>1</li>\n</ul>\n"},
+		{`render_template('@yen($a', {"a": 1})`, "Error: render_template: @yen butuh (...) sing ditutup"},
+		// Like Blade, an @ right after a word character is text
+		{`render_template('mail@yenora.id', {})`, "mail@yenora.id\n"},
+	})
+}
+
+func TestBreakAndContinue(t *testing.T) {
+	runEvalTests(t, []evalTest{
+		{`$sum = 0
+baleni $x neng [1, 2, 3, 4, 5]
+    menowo $x == 4
+        mandheg
+    $sum += $x
+$sum`, "6"},
+		{`$sum = 0
+baleni $x neng [1, 2, 3, 4]
+    menowo $x % 2 == 0
+        terusno
+    $sum += $x
+$sum`, "4"},
+		{`$i = 0
+track bener
+    $i += 1
+    menowo $i >= 3
+        break
+$i`, "3"},
+		// Only the innermost loop is left
+		{`$n = 0
+baleni $a neng [1, 2]
+    baleni $b neng [1, 2, 3]
+        menowo $b == 2
+            mandheg
+        $n += 1
+$n`, "2"},
+		{`$n = 0
+baleni $i neng deret(5)
+    continue
+    $n += 1
+$n`, "0"},
+		{"mandheg", "Error: 'break' kudu neng njero baleni"},
+		{`$f = garap()
+    terusno
+f()`, "Error: 'continue' kudu neng njero baleni"},
+	})
+}
+
+func TestElseIfChains(t *testing.T) {
+	grade := `$grade = garap($n)
+    menowo $n >= 90
+        balekno "A"
+    yenora menowo $n >= 75
+        balekno "B"
+    yenora menowo $n >= 60
+        balekno "C"
+    yenora
+        balekno "D"
+`
+	runEvalTests(t, []evalTest{
+		{grade + "grade(95)", "A"},
+		{grade + "grade(80)", "B"},
+		{grade + "grade(60)", "C"},
+		{grade + "grade(10)", "D"},
+		// Without a final yenora an unmatched chain is NULL
+		{`menowo salah
+    1
+yenora menowo salah
+    2`, "nil"},
+		{`$x = 0
+menowo bener
+    $x = 1
+yenora menowo bener
+    $x = 2
+$x`, "1"},
+	})
+}
+
+func TestTemplateElseIf(t *testing.T) {
+	tpl := `render_template('@yen($n > 1)\nmany\n@yenora_yen($n == 1)\none\n@yenora\nnone\n@punkyan_yen', {"n": %s})`
+	runEvalTests(t, []evalTest{
+		{strings.Replace(tpl, "%s", "5", 1), "many\n"},
+		{strings.Replace(tpl, "%s", "1", 1), "one\n"},
+		{strings.Replace(tpl, "%s", "0", 1), "none\n"},
+		// An empty branch still compiles
+		{`render_template('@yen(bener)\n@yenora\nx\n@punkyan_yen', {})`, ""},
+	})
+}
+
+func TestIndexAssignment(t *testing.T) {
+	runEvalTests(t, []evalTest{
+		{`$a = [1, 2, 3]
+$a[1] = 20
+$a`, "[1, 20, 3]"},
+		{`$a = [1, 2, 3]
+$a[-1] = 30
+$a`, "[1, 2, 30]"},
+		{`$a = [1]
+$a[5] = 2`, "IndexError: array index 5 out of range (dowo 1)"},
+		{`$h = {"a": 1}
+$h["a"] = 2
+$h["b"] = 3
+[$h["a"], $h["b"], dowo($h)]`, "[2, 3, 2]"},
+		{`$h = {"db": {"path": "x"}}
+$h["db"]["path"] = "y"
+$h["db"]["path"]`, "y"},
+		{`gerombolan Conf
+    garap init()
+        $this.config = {"db": {}}
+$c = Conf()
+$c.config["db"]["path"] = "app.db"
+$c.config["db"]["path"]`, "app.db"},
+		{`$s = "abc"
+$s[0] = "x"`, "Error: index assignment not supported: STRING"},
+		{"5 = 1", "Error: invalid assignment target"},
+	})
+}
+
+func TestCompoundAssignment(t *testing.T) {
+	runEvalTests(t, []evalTest{
+		{"$i = 1\n$i += 2\n$i", "3"},
+		{"$i = 10\n$i -= 4\n$i", "6"},
+		{"$i = 3\n$i *= 4\n$i", "12"},
+		{"$i = 9\n$i /= 2\n$i", "4"},
+		{"$i = 9\n$i %= 4\n$i", "1"},
+		{"$f = 1.5\n$f *= 2\n$f", "3.0"},
+		{`$s = "a"
+$s += "b"
+$s`, "ab"},
+		{"$a = [1, 2]\n$a[0] += 10\n$a", "[11, 2]"},
+		{`$h = {"n": 1}
+$h["n"] *= 5
+$h["n"]`, "5"},
+		{`gerombolan Counter
+    garap init()
+        $this.n = 0
+    garap tick()
+        $this.n += 1
+$c = Counter()
+$c.tick()
+$c.tick()
+$c.n`, "2"},
+		{"$i = 1\n$i++\n$i++\n$i--\n$i", "2"},
+		{"$a = [5]\n$a[0]++\n$a[0]", "6"},
+		// Same type rules as the infix operators
+		{"$i = 1\n$i /= 0", "ZeroDivisionError: Waduh, pembagian nol kui ora iso!"},
+		{`$s = "a"
+$s -= "b"`, "Error: unknown operator: STRING - STRING"},
+		{"$ora_ono += 1", "Error: Lha, 'ora_ono' kok ora ono?"},
+	})
+}
+
+func TestTryCatchFinally(t *testing.T) {
+	runEvalTests(t, []evalTest{
+		{`cobo
+    1 / 0
+cekel $e
+    $e["kind"] + ": " + $e["message"]`, "ZeroDivisionError: Waduh, pembagian nol kui ora iso!"},
+		{`$log = ""
+cobo
+    $log += "a"
+cekel $e
+    $log += "b"
+pungkasan
+    $log += "c"
+$log`, "ac"},
+		{`$log = ""
+cobo
+    uncalno "boom"
+cekel $e
+    $log += $e["message"]
+pungkasan
+    $log += "!"
+$log`, "boom!"},
+		{`cobo
+    uncalno {"kind": "HttpError", "message": "ora ketemu", "code": 404}
+cekel $e
+    [$e["kind"], $e["code"]]`, "[HttpError, 404]"},
+		{`gerombolan NotFound
+    garap init($message)
+        $this.message = $message
+cobo
+    uncalno NotFound("ilang")
+cekel $e
+    $e.message`, "ilang"},
+		{`uncalno {"kind": "HttpError", "message": "ora ketemu"}`, "HttpError: ora ketemu"},
+		{`$f = garap()
+    cobo
+        balekno 1
+    pungkasan
+        balekno 2
+f()`, "2"},
+		// Rethrowing keeps the original kind instead of a plain Error
+		{`$a = [1]
+cobo
+    $a[5] = 2
+cekel $e
+    uncalno $e`, "IndexError: array index 5 out of range (dowo 1)"},
+		{`cobo
+    1 / 0
+cekel $e
+    $e = {"message": "anyar"}
+    uncalno $e`, "Error: anyar"},
+	})
+}
+
+func TestRethrowKeepsOriginalError(t *testing.T) {
+	obj := testEval(t, `$inner = garap()
+    balekno 1 / 0
+cobo
+    inner()
+cekel $e
+    uncalno $e`)
+	err, ok := obj.(*object.Error)
+	if !ok {
+		t.Fatalf("expected an error, got %s", describe(obj))
+	}
+	if err.Kind != "ZeroDivisionError" {
+		t.Errorf("kind: got %s, want ZeroDivisionError", err.Kind)
+	}
+	if err.Pos.Line != 2 {
+		t.Errorf("position: got line %d, want 2 where the error was raised", err.Pos.Line)
+	}
+	if len(err.Stack) != 1 || err.Stack[0].Function != "inner" {
+		t.Errorf("stack: got %v, want the inner frame", err.Stack)
+	}
+}
+
+func TestErrorPositions(t *testing.T) {
+	tests := []struct {
+		input  string
+		line   int
+		column int
+	}{
+		// Identifiers are positioned after their $
+		{"$x = 1\n$ora_ono", 2, 2},
+		{"$x = 1\n$y = 2 + $ora_ono", 2, 11},
+		{"$f = garap()\n    balekno 1 / 0\nf()", 2, 15},
+		// An if without an else has a nil Alternative
+		{"menowo bener\n    1 / 0", 2, 7},
+		{"$f = garap($x)\n    menowo $x > 0\n        balekno $x / 0\nf(1)", 3, 20},
+	}
+	for _, tt := range tests {
+		obj := testEval(t, tt.input)
+		err, ok := obj.(*object.Error)
+		if !ok {
+			t.Errorf("%q: expected an error, got %s", tt.input, describe(obj))
+			continue
+		}
+		if err.Pos.File != "test.wlf" || err.Pos.Line != tt.line || err.Pos.Column != tt.column {
+			t.Errorf("%q: got %s, want test.wlf:%d:%d", tt.input, err.Pos, tt.line, tt.column)
+		}
+	}
+}
+
+func TestErrorAtNilNode(t *testing.T) {
+	// A typed nil node panics when evaluated; the recovered error must
+	// not panic again while looking for a position
+	obj := Eval((*ast.BlockStatement)(nil), object.NewEnvironment())
+	if got := describe(obj); !strings.HasPrefix(got, "Error: Kahanan darurat (Panic)!") {
+		t.Errorf("got %s, want the recovered panic error", got)
+	}
+}
+
+func TestFormatTraceback(t *testing.T) {
+	obj := testEval(t, `$inner = garap()
+    balekno 1 / 0
+$outer = garap()
+    balekno inner()
+outer()`)
+	err, ok := obj.(*object.Error)
+	if !ok {
+		t.Fatalf("expected an error, got %s", describe(obj))
+	}
+	expected := `Traceback (most recent call last):
+  File "test.wlf", line 5, in <module>
+  File "test.wlf", line 4, in outer
+  File "test.wlf", line 2, in inner
+ZeroDivisionError: Waduh, pembagian nol kui ora iso!`
+	if got := FormatTraceback(err); got != expected {
+		t.Errorf("got:\n%s\nwant:\n%s", got, expected)
+	}
+}
+
+func TestStringLiterals(t *testing.T) {
+	runEvalTests(t, []evalTest{
+		{`"a\tb"`, "a\tb"},
+		{`"say \"hi\""`, `say "hi"`},
+		{`"line\nnext"`, "line\nnext"},
+		{`"café"`, "café"},
+		{`"back\\slash"`, `back\slash`},
+		{`'single $quoted'`, "single $quoted"},
+		{`'it\'s'`, "it's"},
+		{"`raw \\n $x`", `raw \n $x`},
+		// The newline right after the opening quotes is dropped
+		{"\"\"\"\nSELECT *\nFROM users\"\"\"", "SELECT *\nFROM users"},
+		{`"ab" + 'cd'`, "abcd"},
+	})
+}
+
+func TestStringInterpolation(t *testing.T) {
+	runEvalTests(t, []evalTest{
+		{`$jeneng = "Sari"
+"Halo $jeneng!"`, "Halo Sari!"},
+		{`$umur = 20
+"Umur {$umur + 1}"`, "Umur 21"},
+		{`$a = [10, 20]
+"$a[1] lan {$a[0]}"`, "20 lan 10"},
+		// A dot after a plain variable is text
+		{`$name = "home"
+"views/$name.wlf"`, "views/home.wlf"},
+		{`$name = "home"
+"views/{$name}.wlf"`, "views/home.wlf"},
+		{`gerombolan User
+    garap init($name)
+        $this.name = $name
+    garap greet()
+        balekno "Aku $this.name."
+User("Budi").greet()`, "Aku Budi."},
+		{`gerombolan User
+    garap init($name)
+        $this.name = $name
+$u = User("Budi")
+"{$u.name}"`, "Budi"},
+		{`"nilai: {$ora_ono}"`, "Error: Lha, 'ora_ono' kok ora ono?"},
+		{`"Rego \$5 \{x}"`, "Rego $5 {x}"},
+		{`'Rego $5'`, "Rego $5"},
+		// A $ right after or before word characters
+		{`$total = 3
+"rp$total,-"`, "rp3,-"},
+		{`$total = 3
+"costs \$total"`, "costs $total"},
+		{`"a$1b US$ 5 $"`, "a$1b US$ 5 $"},
+		{`"costs $total"`, "Error: Lha, 'total' kok ora ono?"},
+	})
+}
+
+func TestUnicodeStrings(t *testing.T) {
+	runEvalTests(t, []evalTest{
+		{`dowo("é")`, "1"},
+		{`dowo("wolf🐺")`, "5"},
+		{`string_byte_len("é")`, "2"},
+		{`"héllo"[1]`, "é"},
+		{`"héllo"[-1]`, "o"},
+		{`string_bytes("é")`, "[195, 169]"},
+		{`string_from_bytes([195, 169])`, "é"},
+		{`$kopi_ñ = 1
+$kopi_ñ + 1`, "2"},
+		{`$out = ""
+baleni $c neng "aé"
+    $out = "{$c}-{$out}"
+$out`, "é-a-"},
+	})
+}
+
+func TestSlicing(t *testing.T) {
+	runEvalTests(t, []evalTest{
+		{"[1, 2, 3, 4, 5][1:3]", "[2, 3]"},
+		{"[1, 2, 3, 4, 5][:2]", "[1, 2]"},
+		{"[1, 2, 3, 4, 5][3:]", "[4, 5]"},
+		{"[1, 2, 3, 4, 5][-2:]", "[4, 5]"},
+		{"[1, 2, 3, 4, 5][::2]", "[1, 3, 5]"},
+		{"[1, 2, 3, 4, 5][::-1]", "[5, 4, 3, 2, 1]"},
+		{"[1, 2, 3][5:10]", "[]"},
+		{`"wolf404"[:4]`, "wolf"},
+		{`"héllo"[1:3]`, "él"},
+		{`"abc"[::-1]`, "cba"},
+		{"[1, 2, 3][-1]", "3"},
+		{"[1, 2, 3][::0]", "Error: step slice ora oleh 0"},
+		// Out of range is kopong unless strict mode is on
+		{"[1, 2, 3][10]", "nil"},
+		{`"abc"[5]`, "nil"},
+	})
+}
+
+func TestStrictIndexing(t *testing.T) {
+	StrictIndexing = true
+	defer func() { StrictIndexing = false }()
+
+	runEvalTests(t, []evalTest{
+		{"[1, 2, 3][10]", "IndexError: indeks 10 ngluwihi dowo 3"},
+		{"[1, 2, 3][-4]", "IndexError: indeks -4 ngluwihi dowo 3"},
+		{`"abc"[3]`, "IndexError: indeks 3 ngluwihi dowo 3"},
+		{"[1, 2, 3][2]", "3"},
+		// Slices clamp even in strict mode
+		{"[1, 2, 3][1:10]", "[2, 3]"},
+	})
+}
+
+func TestFunctionParameters(t *testing.T) {
+	salam := `$salam = garap($jeneng, $sapaan = "Halo", ...$liyane)
+    balekno "{$sapaan} {$jeneng} {$liyane}"
+`
+	runEvalTests(t, []evalTest{
+		{salam + `salam("Budi")`, "Halo Budi []"},
+		{salam + `salam("Budi", "Hai")`, "Hai Budi []"},
+		{salam + `salam("Budi", "Hai", 1, 2)`, "Hai Budi [1, 2]"},
+		{salam + `salam(jeneng: "Ani", sapaan: "Sugeng")`, "Sugeng Ani []"},
+		{salam + `salam("Ani", sapaan: "Sugeng")`, "Sugeng Ani []"},
+		{salam + `salam()`, "ArgumentError: salam: kurang argumen $jeneng"},
+		{salam + `salam("Ani", jeneng: "Budi")`, "ArgumentError: salam: argumen $jeneng diwenehi kaping pindho"},
+		{salam + `salam("Ani", umur: 3)`, "ArgumentError: salam: ora nduwe parameter $umur"},
+		{`$f = garap($a)
+    balekno $a
+$f(1, 2)`, "ArgumentError: f: kakehan argumen, butuhe paling akeh 1, oleh 2"},
+		// Defaults are evaluated per call and may use earlier parameters
+		{`$f = garap($a, $b = $a * 2)
+    balekno $b
+$f(4)`, "8"},
+		{`$f = garap($h = {})
+    $h[string(dowo($h))] = 1
+    balekno dowo($h)
+$f()
+$f()`, "1"},
+		{"$f = garap(...$xs)\n    balekno $xs\n$f()", "[]"},
+	})
+}
+
+func TestNamedGarapIsAnExpression(t *testing.T) {
+	// A named garap on its own line is only a value; the name labels
+	// the function in tracebacks but binds nothing
+	runEvalTests(t, []evalTest{
+		{"garap f()\n    balekno 1\nf()", "Error: Lha, 'f' kok ora ono?"},
+		{"$sum = hunt($a, $b)\n    bring $a + $b\nsum(1, 2)", "3"},
+	})
+
+	obj := testEval(t, "$g = garap sum()\n    balekno 1 / 0\n$g()")
+	err, ok := obj.(*object.Error)
+	if !ok || len(err.Stack) == 0 || err.Stack[0].Function != "sum" {
+		t.Errorf("expected the traceback to name sum, got %s", describe(obj))
+	}
+}
+
+func TestDestructuring(t *testing.T) {
+	runEvalTests(t, []evalTest{
+		{"[$a, $b] = [1, 2]\n$a + $b", "3"},
+		{"$a = 1\n$b = 2\n[$a, $b] = [$b, $a]\n[$a, $b]", "[2, 1]"},
+		{"[$a, [$b, $c]] = [1, [2, 3]]\n$c", "3"},
+		{`{"username": $u, "password": $p} = {"username": "ani", "password": "x", "extra": 1}
+$u`, "ani"},
+		{`$jarak = garap([$x, $y])
+    balekno $x * $x + $y * $y
+$jarak([3, 4])`, "25"},
+		{`$out = ""
+baleni [$jeneng, $umur] neng [["Ani", 20], ["Budi", 25]]
+    $out += "{$jeneng}:{$umur} "
+$out`, "Ani:20 Budi:25 "},
+		{"[$a, $b] = [1, 2, 3]", "ValueError: mbongkar array butuh 2 nilai, oleh 3"},
+		{`{"a": $a} = {"b": 1}`, `ValueError: mbongkar hash butuh kunci "a", ora ono`},
+		{"[$a, 1] = [1, 2]", "Error: invalid assignment target"},
+		{"[$a, $b] = 5", "ValueError: ora iso mbongkar INTEGER dadi array"},
+	})
+}
+
+func TestMatchExpression(t *testing.T) {
+	runEvalTests(t, []evalTest{
+		{`cocokno 200
+    200 => "ok"
+    _ => "liyane"`, "ok"},
+		{`cocokno "GET"
+    "POST", "GET" => "metode"
+    _ => "liyane"`, "metode"},
+		{`cocokno [1, 2, 3]
+    [$a, ...$sisa] => $sisa`, "[2, 3]"},
+		{`cocokno {"code": 404, "x": 1}
+    {"code": 200} => "ok"
+    {"code": INTEGER $c} menowo $c >= 400 => $c`, "404"},
+		{`cocokno 3.5
+    STRING => "s"
+    FLOAT $f => $f * 2`, "7.0"},
+		{`cocokno 5
+    1 => "siji"`, "MatchError: cocokno: ora ono pola sing cocok karo 5"},
+		{`cocokno -1
+    -1 => "min"
+    _ => "liyane"`, "min"},
+		{`gerombolan Status
+    tetep AKTIF = 1
+    tetep MATI = 0
+cocokno 0
+    Status.AKTIF => "aktif"
+    Status.MATI => "mati"`, "mati"},
+		// Bindings of an arm that is not taken don't leak out
+		{`$c = "orig"
+cocokno {"code": 200}
+    {"code": $c} menowo $c > 500 => "gedhe"
+    _ => "cilik"
+$c`, "orig"},
+		{`$c = "orig"
+cocokno [1, "x"]
+    [$c, 2] => "ora"
+    _ => "liyane"
+$c`, "orig"},
+		// ...while those of the taken arm stay visible
+		{`cocokno [1, 2]
+    [$a, $b] => kopong
+$a + $b`, "3"},
+		{`$f = garap($res)
+    cocokno $res
+        {"error": bener} => balekno $res["code"] utowo 401
+        _ => kopong
+    balekno "lanjut"
+$r = [$f({"error": bener}), $f({"error": bener, "code": 403}), $f({"ok": 1})]
+$r`, "[401, 403, lanjut]"},
+	})
+}
+
+func TestClosuresAndScoping(t *testing.T) {
+	runEvalTests(t, []evalTest{
+		{`$pangetung = garap()
+    $cacah = 0
+    balekno garap()
+        $cacah += 1
+        balekno $cacah
+$c = $pangetung()
+$c()
+$c()`, "2"},
+		// Each call of the outer function gets its own captured variable
+		{`$pangetung = garap()
+    $cacah = 0
+    balekno garap()
+        $cacah += 1
+        balekno $cacah
+$a = $pangetung()
+$b = $pangetung()
+$a()
+$a()
+$b()`, "1"},
+		// Module variables are updated like any other enclosing binding...
+		{`$total = 0
+$f = garap()
+    $total = 5
+$f()
+$total`, "5"},
+		{`$hits = 0
+$count = garap()
+    $hits = $hits + 1
+$count()
+$count()
+$hits`, "2"},
+		// ...unless shadowed with lokal
+		{`$total = 0
+$f = garap()
+    lokal $total = 5
+$f()
+$total`, "0"},
+		// jagad reaches the module scope even before it has the variable
+		{`$f = garap($n)
+    jagad $total
+    $total = $n
+$f(3)
+$total`, "3"},
+		{`$total = 0
+$f = garap($n)
+    jagad $total
+    $total += $n
+$f(2)
+$f(3)
+$total`, "5"},
+		{`$outer = garap()
+    $x = 1
+    $inner = garap()
+        lokal $x = 99
+        balekno $x
+    $inner()
+    balekno $x
+$outer()`, "1"},
+		{`$f = garap()
+    $baru = 1
+$f()
+$baru`, "Error: Lha, 'baru' kok ora ono?"},
+		// Methods share instance state through $this, not captured locals
+		{`gerombolan Counter
+    garap init()
+        $this.n = 0
+    garap tick()
+        $n = 100
+        $this.n += 1
+        balekno $this.n
+$c = Counter()
+$c.tick()
+$c.tick()`, "2"},
+	})
+}
+
+func TestSuperCalls(t *testing.T) {
+	animals := `gerombolan Kewan
+    garap init($jeneng)
+        $this.jeneng = $jeneng
+    garap suara()
+        balekno "..."
+    garap kenalan()
+        balekno $this.jeneng + ": " + $this.suara()
+
+gerombolan Asu : Kewan
+    garap init($jeneng, $wulu)
+        induk.init($jeneng)
+        $this.wulu = $wulu
+    garap suara()
+        balekno "guk " + induk.suara()
+
+gerombolan Kirik : Asu
+    garap suara()
+        balekno "cilik " + induk.suara()
+`
+	runEvalTests(t, []evalTest{
+		{animals + `Asu("Bleki", "ireng").kenalan()`, "Bleki: guk ..."},
+		{animals + `Asu("Bleki", "ireng").wulu`, "ireng"},
+		// init is inherited when a subclass has none
+		{animals + `Kirik("Cemplon", "putih").kenalan()`, "Cemplon: cilik guk ..."},
+		{animals + `instance_of(Kirik("a", "b"), Kewan)`, "true"},
+		{animals + `instance_of(Kewan("a"), Asu)`, "false"},
+		{animals + `is_a(Asu("a", "b"), "Kewan")`, "true"},
+		{"induk.f()", "Error: induk mung iso dienggo neng method gerombolan sing nduwe induk"},
+		{`gerombolan A
+    garap f()
+        balekno induk.f()
+A().f()`, "Error: induk mung iso dienggo neng method gerombolan sing nduwe induk"},
+	})
+}
+
+func TestStaticMembers(t *testing.T) {
+	model := `gerombolan Model
+    tetep TABLE = "models"
+    statis $cacah = 0
+    statis garap tabel()
+        balekno "tbl_" + $this.TABLE
+    statis garap gawe()
+        Model.cacah += 1
+        balekno $this()
+
+gerombolan User : Model
+    tetep TABLE = "users"
+`
+	runEvalTests(t, []evalTest{
+		{model + "Model.TABLE", "models"},
+		{model + "User.TABLE", "users"},
+		{model + "Model.tabel()", "tbl_models"},
+		// Static methods are inherited, with $this bound to the class called on
+		{model + "User.tabel()", "tbl_users"},
+		{model + "User.gawe()\nUser.gawe()\nModel.cacah", "2"},
+		{model + "instance_of(User.gawe(), User)", "true"},
+		{model + `Model.TABLE = "x"`, "Error: Model.TABLE iku konstanta, ora iso diganti"},
+		{"tetep A = 1", "Error: tetep mung iso neng njero gerombolan"},
+		{"statis $b = 1", "Error: statis mung iso neng njero gerombolan"},
+		{model + "Model.ora_ono", "Error: gerombolan Model ora nduwe ora_ono"},
+		// Instances read constants through their class
+		{model + "User().TABLE", "users"},
+	})
+}
+
+func TestContractsAndTraits(t *testing.T) {
+	decls := `kontrak Muni
+    garap suara()
+
+kontrak Kewan : Muni
+    garap jeneng()
+
+sifat Ramah
+    garap salam()
+        balekno "halo, aku " + $this.jeneng()
+
+sifat Sopan
+    garap salam()
+        balekno "sugeng"
+`
+	runEvalTests(t, []evalTest{
+		{decls + `gerombolan Kucing netepi Kewan
+    nyampur Ramah
+    garap suara()
+        balekno "meong"
+    garap jeneng()
+        balekno "Tom"
+Kucing().salam()`, "halo, aku Tom"},
+		{decls + `gerombolan Kucing netepi Kewan
+    garap suara()
+        balekno "meong"
+    garap jeneng()
+        balekno "Tom"
+is_a(Kucing(), "Muni")`, "true"},
+		{decls + `gerombolan Watu netepi Kewan
+    garap suara()
+        balekno ""`, "Error: gerombolan Watu ora netepi kontrak Kewan: method jeneng() ora ono"},
+		{decls + `gerombolan Watu netepi Muni
+    garap suara($a, $b)
+        balekno ""`, "Error: gerombolan Watu ora netepi kontrak Muni: method suara kudu iso nampa 0 parameter"},
+		// A class that fails its kontrak is not left bound
+		{decls + `cobo
+    gerombolan Watu netepi Kewan
+        garap suara()
+            balekno ""
+cekel $e
+    kopong
+Watu`, "Error: Lha, 'Watu' kok ora ono?"},
+		{decls + `$Watu = "lawas"
+cobo
+    gerombolan Watu netepi Kewan
+        garap suara()
+            balekno ""
+cekel $e
+    kopong
+$Watu`, "lawas"},
+		{decls + `gerombolan Bingung
+    nyampur Ramah, Sopan`, "Error: method salam ono neng sifat Ramah lan Sopan, tulisen dhewe neng Bingung"},
+		{decls + `gerombolan Bingung
+    nyampur Ramah, Sopan
+    garap salam()
+        balekno "dhewe"
+Bingung().salam()`, "dhewe"},
+		// Members can still refer to the class while it is being declared
+		{`gerombolan Model
+    tetep A = 1
+    tetep B = Model.A + 1
+Model.B`, "2"},
+		{"gerombolan K netepi OraOno\n    kopong", "Error: kontrak ora ketemu: OraOno"},
+	})
+}
+
+func TestMagicMethods(t *testing.T) {
+	money := `gerombolan Duit
+    garap init($n)
+        $this.n = $n
+    garap __string()
+        balekno "Rp {$this.n}"
+    garap __add($liyane)
+        balekno Duit($this.n + $liyane.n)
+    garap __eq($liyane)
+        balekno $this.n == $liyane.n
+    garap __json()
+        balekno $this.n
+`
+	runEvalTests(t, []evalTest{
+		{money + `string(Duit(5))`, "Rp 5"},
+		{money + `string(Duit(2) + Duit(3))`, "Rp 5"},
+		{money + `Duit(1) == Duit(1)`, "true"},
+		{money + `Duit(1) != Duit(2)`, "true"},
+		{money + `$d = Duit(7)
+"total: $d"`, "total: Rp 7"},
+		{money + `http_json({"rego": Duit(9)})`, `{"rego":9}`},
+		// Instances nested in arrays and hashes use __string too
+		{money + `string([Duit(1), Duit(2)])`, "[Rp 1, Rp 2]"},
+		{money + `string({"a": [Duit(3)]})`, "{a: [Rp 3]}"},
+		{money + `$ds = [Duit(4)]
+"{$ds}"`, "[Rp 4]"},
+		{`gerombolan Elek
+    garap __string()
+        balekno 1
+string([Elek()])`, "Error: Elek.__string kudu mbalekno STRING, oleh INTEGER"},
+	})
+}
+
+func TestMagicIndexCallAndIteration(t *testing.T) {
+	runEvalTests(t, []evalTest{
+		{`gerombolan Tas
+    garap init()
+        $this.isi = {}
+    garap __get($k)
+        balekno $this.isi[$k]
+    garap __set($k, $v)
+        $this.isi[$k] = $v
+$t = Tas()
+$t["a"] = 1
+$t["a"]`, "1"},
+		{`gerombolan Tambah
+    garap __call($x)
+        balekno $x + 1
+$f = Tambah()
+$f(41)`, "42"},
+		{`gerombolan Telu
+    garap __iter()
+        balekno [1, 2, 3]
+$sum = 0
+baleni $x neng Telu()
+    $sum += $x
+$sum`, "6"},
+	})
+}
+
+// withModules writes files into a fresh directory and makes it the only
+// module path for the rest of the test.
+func withModules(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	saved := ModulePaths
+	ModulePaths = []string{dir}
+	t.Cleanup(func() { ModulePaths = saved })
+	return dir
+}
+
+func TestModules(t *testing.T) {
+	dir := withModules(t, map[string]string{
+		"a.wlf":     "ekspor $X = 1\n$rahasia = 2\nekspor $tambah = garap($n)\n    balekno $n + $X",
+		"nilai.wlf": "balekno 42",
+		"cyc_a.wlf": "undang \"cyc_b\"",
+		"cyc_b.wlf": "undang \"cyc_a\"",
+		"muat.wlf":  "ekspor $muat = garap()\n    balekno nganggo(\"balik\")",
+		"balik.wlf": "undang \"muat\"\nbalekno \"oke\"",
+		"cacah.wlf": "$hits = 0\nekspor $catat = garap()\n    $hits += 1\n    balekno $hits",
+	})
+
+	runEvalTests(t, []evalTest{
+		{"undang \"a\"\n$tambah($X)", "2"},
+		{"undang \"a\"\n$rahasia", "Error: Lha, 'rahasia' kok ora ono?"},
+		{"undang \"a.wlf\" dadi A\nA.tambah(10)", "11"},
+		{"undang \"a\" dadi A\nA.rahasia", "Error: modul a ora ngekspor rahasia"},
+		{"undang \"a\" dadi A\nundang \"a.wlf\" dadi B\nA == B", "true"},
+		{"nganggo(\"nilai\")", "42"},
+		{"undang \"cyc_a\"", "ImportError: modul muter: cyc_a -> cyc_b -> cyc_a"},
+		{"undang \"ilang\"", "ImportError: modul ilang ora ketemu neng " + dir},
+		// A module that finished loading can be imported back later on
+		{"undang \"muat\"\n$muat()", "oke"},
+		// Functions of a module update its own variables
+		{"undang \"cacah\" dadi C\nC.catat()\nC.catat()", "2"},
+	})
+}
+
+func TestModulesLoadConcurrently(t *testing.T) {
+	// The module counts its runs through a builtin and takes a while, so
+	// the imports overlap
+	var runs int32
+	builtins["cacah_muat"] = &object.Builtin{Fn: func(args ...object.Object) object.Object {
+		atomic.AddInt32(&runs, 1)
+		return NULL
+	}}
+	t.Cleanup(func() { delete(builtins, "cacah_muat") })
+	withModules(t, map[string]string{
+		"bareng.wlf": "cacah_muat()\n$n = 0\nbaleni $i neng deret(20000)\n    $n += $i\nekspor $X = 1",
+	})
+
+	const n = 8
+	loaded := make([]*object.Module, n)
+	errs := make([]object.Object, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			loaded[i], errs[i] = loadModule("bareng", object.NewEnvironment())
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < n; i++ {
+		if errs[i] != nil {
+			t.Fatalf("load %d: %s", i, describe(errs[i]))
+		}
+		if loaded[i] != loaded[0] {
+			t.Errorf("load %d got a different module than load 0", i)
+		}
+	}
+	if runs != 1 {
+		t.Errorf("module ran %d times, want 1", runs)
+	}
+}
+
+// Two goroutines importing each other's modules must not wait for each
+// other forever.
+func TestModulesCycleAcrossGoroutines(t *testing.T) {
+	withModules(t, map[string]string{
+		"sisih_a.wlf": "$n = 0\nbaleni $i neng deret(20000)\n    $n += $i\nundang \"sisih_b\"",
+		"sisih_b.wlf": "$n = 0\nbaleni $i neng deret(20000)\n    $n += $i\nundang \"sisih_a\"",
+	})
+
+	errs := make([]object.Object, 2)
+	done := make(chan struct{})
+	go func() {
+		var wg sync.WaitGroup
+		for i, name := range []string{"sisih_a", "sisih_b"} {
+			wg.Add(1)
+			go func(i int, name string) {
+				defer wg.Done()
+				_, errs[i] = loadModule(name, object.NewEnvironment())
+			}(i, name)
+		}
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("imports deadlocked")
+	}
+	for i, err := range errs {
+		if err == nil || !strings.Contains(describe(err), "ImportError: modul muter") {
+			t.Errorf("load %d: got %v, want an import cycle error", i, err)
+		}
+	}
+}
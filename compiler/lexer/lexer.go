@@ -1,7 +1,11 @@
 package lexer
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 // TokenType represents the type of token
@@ -11,14 +15,18 @@ const (
 	// Special tokens
 	TOKEN_EOF TokenType = iota
 	TOKEN_ILLEGAL
+	TOKEN_ERROR       // lexical error, Literal holds the message
+	TOKEN_COMMENT     // only emitted in comment-preserving mode
+	TOKEN_DOC_COMMENT // /// text, attached to the next garap or gerombolan
 
 	// Identifiers and literals
-	TOKEN_IDENT  // variable names, function names
-	TOKEN_INT    // 123
-	TOKEN_FLOAT  // 123.45
-	TOKEN_STRING // "hello"
-	TOKEN_TRUE   // true
-	TOKEN_FALSE  // false
+	TOKEN_IDENT        // variable names, function names
+	TOKEN_INT          // 123
+	TOKEN_FLOAT        // 123.45
+	TOKEN_STRING       // "hello"
+	TOKEN_INTERPOLATED // "Halo {$jeneng}", Parts holds the pieces
+	TOKEN_TRUE         // true
+	TOKEN_FALSE        // false
 
 	// Operators
 	TOKEN_ASSIGN   // =
@@ -27,6 +35,7 @@ const (
 	TOKEN_ASTERISK // *
 	TOKEN_SLASH    // /
 	TOKEN_PERCENT  // %
+	TOKEN_POWER    // **
 	TOKEN_EQ       // ==
 	TOKEN_NEQ      // !=
 	TOKEN_LT       // <
@@ -37,6 +46,14 @@ const (
 	TOKEN_OR       // or
 	TOKEN_NOT      // not
 
+	TOKEN_PLUS_ASSIGN     // +=
+	TOKEN_MINUS_ASSIGN    // -=
+	TOKEN_ASTERISK_ASSIGN // *=
+	TOKEN_SLASH_ASSIGN    // /=
+	TOKEN_PERCENT_ASSIGN  // %=
+	TOKEN_INCREMENT       // ++
+	TOKEN_DECREMENT       // --
+
 	// Delimiters
 	TOKEN_COMMA    // ,
 	TOKEN_COLON    // :
@@ -52,20 +69,44 @@ const (
 	TOKEN_DEDENT   // dedentation
 
 	// Keywords (Wolf404 specific)
-	TOKEN_HUNT    // hunt (function definition)
-	TOKEN_SNIFF   // sniff (if)
-	TOKEN_MISSING // missing (else/elif)
-	TOKEN_TRACK   // track (for/while)
-	TOKEN_BRING   // bring (return)
-	TOKEN_HOWL    // howl (print/log)
-	TOKEN_SUMMON  // summon (import)
-	TOKEN_PACK    // pack (class/module)
-	TOKEN_IN      // in
-	TOKEN_RANGE   // range
-	TOKEN_NIL     // nil
-	TOKEN_PROWL   // prowl (go routine)
-	TOKEN_MOLD    // mold (class)
-	TOKEN_DOT     // .
+	TOKEN_HUNT     // hunt (function definition)
+	TOKEN_SNIFF    // sniff (if)
+	TOKEN_MISSING  // missing (else/elif)
+	TOKEN_TRACK    // track (for/while)
+	TOKEN_BRING    // bring (return)
+	TOKEN_HOWL     // howl (print/log)
+	TOKEN_SUMMON   // summon (import)
+	TOKEN_PACK     // pack (class/module)
+	TOKEN_IN       // in
+	TOKEN_RANGE    // range
+	TOKEN_NIL      // nil
+	TOKEN_PROWL    // prowl (go routine)
+	TOKEN_MOLD     // mold (class)
+	TOKEN_BREAK    // break
+	TOKEN_CONTINUE // continue
+	TOKEN_TRY      // try
+	TOKEN_CATCH    // catch
+	TOKEN_FINALLY  // finally
+	TOKEN_THROW    // throw
+	TOKEN_DOT      // .
+	TOKEN_ELLIPSIS // ...
+	TOKEN_MATCH    // match
+	TOKEN_ARROW    // =>
+	TOKEN_LOCAL    // local
+	TOKEN_GLOBAL   // global
+	TOKEN_SUPER    // super
+	TOKEN_STATIC   // static
+	TOKEN_CONST    // const
+
+	// Interfaces and traits
+	TOKEN_INTERFACE  // interface
+	TOKEN_TRAIT      // trait
+	TOKEN_IMPLEMENTS // implements
+	TOKEN_USE        // use (trait)
+
+	// Modules
+	TOKEN_EXPORT // export
+	TOKEN_AS     // as (import alias)
 )
 
 var keywords = map[string]TokenType{
@@ -105,26 +146,73 @@ var keywords = map[string]TokenType{
 	"not":        TOKEN_NOT,
 	"playon":     TOKEN_PROWL,
 	"prowl":      TOKEN_PROWL,
+	"mandheg":    TOKEN_BREAK,
+	"break":      TOKEN_BREAK,
+	"terusno":    TOKEN_CONTINUE,
+	"continue":   TOKEN_CONTINUE,
+	"cobo":       TOKEN_TRY,
+	"try":        TOKEN_TRY,
+	"cekel":      TOKEN_CATCH,
+	"catch":      TOKEN_CATCH,
+	"pungkasan":  TOKEN_FINALLY,
+	"finally":    TOKEN_FINALLY,
+	"uncalno":    TOKEN_THROW,
+	"throw":      TOKEN_THROW,
+	"cocokno":    TOKEN_MATCH,
+	"match":      TOKEN_MATCH,
+	"lokal":      TOKEN_LOCAL,
+	"local":      TOKEN_LOCAL,
+	"jagad":      TOKEN_GLOBAL,
+	"global":     TOKEN_GLOBAL,
+	"induk":      TOKEN_SUPER,
+	"super":      TOKEN_SUPER,
+	"statis":     TOKEN_STATIC,
+	"static":     TOKEN_STATIC,
+	"tetep":      TOKEN_CONST,
+	"const":      TOKEN_CONST,
+	"kontrak":    TOKEN_INTERFACE,
+	"interface":  TOKEN_INTERFACE,
+	"sifat":      TOKEN_TRAIT,
+	"trait":      TOKEN_TRAIT,
+	"netepi":     TOKEN_IMPLEMENTS,
+	"implements": TOKEN_IMPLEMENTS,
+	"nyampur":    TOKEN_USE,
+	"use":        TOKEN_USE,
+	"ekspor":     TOKEN_EXPORT,
+	"export":     TOKEN_EXPORT,
+	"dadi":       TOKEN_AS,
+	"as":         TOKEN_AS,
 }
 
 // Token represents a lexical token
 type Token struct {
 	Type    TokenType
 	Literal string
+	File    string
 	Line    int
 	Column  int
+	Parts   []StringPart // pieces of a TOKEN_INTERPOLATED string
 }
 
 // Lexer represents the lexical analyzer
 type Lexer struct {
 	input        string
-	position     int  // current position in input
-	readPosition int  // current reading position
-	ch           byte // current char
+	file         string // source file name, stamped on every token
+	position     int    // byte offset of ch in input
+	readPosition int    // byte offset of the next char
+	ch           rune   // current char, decoded from UTF-8
 	line         int
-	column       int
+	column       int     // column of ch, counted in runes
 	indentStack  []int   // track indentation levels
 	tokenQueue   []Token // Buffered tokens for DEDENT generation
+
+	// Emit TOKEN_COMMENT tokens instead of skipping comments
+	preserveComments bool
+
+	// End of the last non-blank line, where the next NEWLINE token points
+	pendingNewline bool
+	newlineLine    int
+	newlineColumn  int
 }
 
 // New creates a new Lexer
@@ -140,24 +228,50 @@ func New(input string) *Lexer {
 	return l
 }
 
+// NewWithFile creates a Lexer whose tokens remember the file they came from
+func NewWithFile(input, file string) *Lexer {
+	l := New(input)
+	l.file = file
+	return l
+}
+
+// NewAt creates a Lexer for a snippet of a larger file, such as an
+// interpolated expression, so its tokens carry their real positions.
+func NewAt(input, file string, line, column int) *Lexer {
+	l := &Lexer{
+		input:       input,
+		file:        file,
+		line:        line,
+		column:      column - 1,
+		indentStack: []int{0},
+		tokenQueue:  []Token{},
+	}
+	l.readChar()
+	return l
+}
+
+// PreserveComments makes the lexer emit every comment as a token, so tools
+// like the formatter can round-trip source. The parser skips these tokens.
+func (l *Lexer) PreserveComments() {
+	l.preserveComments = true
+}
+
 // readChar reads the next character
 func (l *Lexer) readChar() {
+	width := 1
 	if l.readPosition >= len(l.input) {
 		l.ch = 0
 	} else {
-		l.ch = l.input[l.readPosition]
+		l.ch, width = utf8.DecodeRuneInString(l.input[l.readPosition:])
 	}
 	l.position = l.readPosition
-	l.readPosition++
+	l.readPosition += width
 	l.column++
 }
 
 // peekChar looks at the next character without advancing
-func (l *Lexer) peekChar() byte {
-	if l.readPosition >= len(l.input) {
-		return 0
-	}
-	return l.input[l.readPosition]
+func (l *Lexer) peekChar() rune {
+	return l.peekCharAt(0)
 }
 
 func (l *Lexer) skipWhitespaceExceptNewline() {
@@ -167,6 +281,12 @@ func (l *Lexer) skipWhitespaceExceptNewline() {
 }
 
 func (l *Lexer) handleNewline() {
+	// Remember where the last non-blank line ended, for diagnostics
+	if !l.pendingNewline {
+		l.pendingNewline = true
+		l.newlineLine, l.newlineColumn = l.line, l.column
+	}
+
 	// Consume the newline
 	l.line++
 	l.column = 0
@@ -189,27 +309,34 @@ func (l *Lexer) handleNewline() {
 		return
 	}
 
+	l.pendingNewline = false
 	currentIndent := l.indentStack[len(l.indentStack)-1]
 
 	if indentLen > currentIndent {
 		l.indentStack = append(l.indentStack, indentLen)
-		l.tokenQueue = append(l.tokenQueue, Token{Type: TOKEN_NEWLINE, Literal: "\n", Line: l.line - 1})
-		l.tokenQueue = append(l.tokenQueue, Token{Type: TOKEN_INDENT, Literal: "INDENT", Line: l.line})
+		l.tokenQueue = append(l.tokenQueue, Token{Type: TOKEN_NEWLINE, Literal: "\n", Line: l.newlineLine, Column: l.newlineColumn})
+		l.tokenQueue = append(l.tokenQueue, Token{Type: TOKEN_INDENT, Literal: "INDENT", Line: l.line, Column: l.column})
 	} else if indentLen < currentIndent {
-		l.tokenQueue = append(l.tokenQueue, Token{Type: TOKEN_NEWLINE, Literal: "\n", Line: l.line - 1})
+		l.tokenQueue = append(l.tokenQueue, Token{Type: TOKEN_NEWLINE, Literal: "\n", Line: l.newlineLine, Column: l.newlineColumn})
 		// Pop dedents until matches
 		for len(l.indentStack) > 1 && indentLen < l.indentStack[len(l.indentStack)-1] {
 			l.indentStack = l.indentStack[:len(l.indentStack)-1]
-			l.tokenQueue = append(l.tokenQueue, Token{Type: TOKEN_DEDENT, Literal: "DEDENT", Line: l.line})
+			l.tokenQueue = append(l.tokenQueue, Token{Type: TOKEN_DEDENT, Literal: "DEDENT", Line: l.line, Column: l.column})
 		}
 	} else {
 		// If equal, just a newline separator
-		l.tokenQueue = append(l.tokenQueue, Token{Type: TOKEN_NEWLINE, Literal: "\n", Line: l.line - 1})
+		l.tokenQueue = append(l.tokenQueue, Token{Type: TOKEN_NEWLINE, Literal: "\n", Line: l.newlineLine, Column: l.newlineColumn})
 	}
 }
 
 // NextToken returns the next token
 func (l *Lexer) NextToken() Token {
+	tok := l.nextToken()
+	tok.File = l.file
+	return tok
+}
+
+func (l *Lexer) nextToken() Token {
 	// 1. Check if we have buffered tokens
 	if len(l.tokenQueue) > 0 {
 		tok := l.tokenQueue[0]
@@ -238,50 +365,72 @@ func (l *Lexer) NextToken() Token {
 			l.readChar()
 		}
 		l.handleNewline()
-		return l.NextToken()
+		return l.nextToken()
 	case '=':
 		if l.peekChar() == '=' {
-			ch := l.ch
-			l.readChar()
-			tok = Token{Type: TOKEN_EQ, Literal: string(ch) + string(l.ch), Line: l.line, Column: l.column}
+			tok = l.newTwoCharToken(TOKEN_EQ)
+		} else if l.peekChar() == '>' {
+			tok = l.newTwoCharToken(TOKEN_ARROW)
 		} else {
 			tok = newToken(TOKEN_ASSIGN, l.ch, l.line, l.column)
 		}
 	case '+':
-		tok = newToken(TOKEN_PLUS, l.ch, l.line, l.column)
+		if l.peekChar() == '=' {
+			tok = l.newTwoCharToken(TOKEN_PLUS_ASSIGN)
+		} else if l.peekChar() == '+' {
+			tok = l.newTwoCharToken(TOKEN_INCREMENT)
+		} else {
+			tok = newToken(TOKEN_PLUS, l.ch, l.line, l.column)
+		}
 	case '-':
-		tok = newToken(TOKEN_MINUS, l.ch, l.line, l.column)
+		if l.peekChar() == '=' {
+			tok = l.newTwoCharToken(TOKEN_MINUS_ASSIGN)
+		} else if l.peekChar() == '-' {
+			tok = l.newTwoCharToken(TOKEN_DECREMENT)
+		} else {
+			tok = newToken(TOKEN_MINUS, l.ch, l.line, l.column)
+		}
 	case '*':
-		tok = newToken(TOKEN_ASTERISK, l.ch, l.line, l.column)
+		if l.peekChar() == '*' {
+			tok = l.newTwoCharToken(TOKEN_POWER)
+		} else if l.peekChar() == '=' {
+			tok = l.newTwoCharToken(TOKEN_ASTERISK_ASSIGN)
+		} else {
+			tok = newToken(TOKEN_ASTERISK, l.ch, l.line, l.column)
+		}
 	case '/':
-		if l.peekChar() == '/' {
-			l.skipComment()
-			return l.NextToken()
+		if l.peekChar() == '/' || l.peekChar() == '*' {
+			if comment, ok := l.readComment(tok); ok {
+				return comment
+			}
+			return l.nextToken()
+		}
+		if l.peekChar() == '=' {
+			tok = l.newTwoCharToken(TOKEN_SLASH_ASSIGN)
+		} else {
+			tok = newToken(TOKEN_SLASH, l.ch, l.line, l.column)
 		}
-		tok = newToken(TOKEN_SLASH, l.ch, l.line, l.column)
 	case '%':
-		tok = newToken(TOKEN_PERCENT, l.ch, l.line, l.column)
+		if l.peekChar() == '=' {
+			tok = l.newTwoCharToken(TOKEN_PERCENT_ASSIGN)
+		} else {
+			tok = newToken(TOKEN_PERCENT, l.ch, l.line, l.column)
+		}
 	case '!':
 		if l.peekChar() == '=' {
-			ch := l.ch
-			l.readChar()
-			tok = Token{Type: TOKEN_NEQ, Literal: string(ch) + string(l.ch), Line: l.line, Column: l.column}
+			tok = l.newTwoCharToken(TOKEN_NEQ)
 		} else {
-			tok = newToken(TOKEN_ILLEGAL, l.ch, l.line, l.column)
+			tok = newToken(TOKEN_NOT, l.ch, l.line, l.column)
 		}
 	case '<':
 		if l.peekChar() == '=' {
-			ch := l.ch
-			l.readChar()
-			tok = Token{Type: TOKEN_LTE, Literal: string(ch) + string(l.ch), Line: l.line, Column: l.column}
+			tok = l.newTwoCharToken(TOKEN_LTE)
 		} else {
 			tok = newToken(TOKEN_LT, l.ch, l.line, l.column)
 		}
 	case '>':
 		if l.peekChar() == '=' {
-			ch := l.ch
-			l.readChar()
-			tok = Token{Type: TOKEN_GTE, Literal: string(ch) + string(l.ch), Line: l.line, Column: l.column}
+			tok = l.newTwoCharToken(TOKEN_GTE)
 		} else {
 			tok = newToken(TOKEN_GT, l.ch, l.line, l.column)
 		}
@@ -290,7 +439,14 @@ func (l *Lexer) NextToken() Token {
 	case ':':
 		tok = newToken(TOKEN_COLON, l.ch, l.line, l.column)
 	case '.':
-		tok = newToken(TOKEN_DOT, l.ch, l.line, l.column)
+		if l.peekChar() == '.' && l.peekCharAt(1) == '.' {
+			column := l.column
+			l.readChar()
+			l.readChar()
+			tok = Token{Type: TOKEN_ELLIPSIS, Literal: "...", Line: l.line, Column: column}
+		} else {
+			tok = newToken(TOKEN_DOT, l.ch, l.line, l.column)
+		}
 	case '(':
 		tok = newToken(TOKEN_LPAREN, l.ch, l.line, l.column)
 	case ')':
@@ -305,9 +461,18 @@ func (l *Lexer) NextToken() Token {
 		tok = newToken(TOKEN_RBRACKET, l.ch, l.line, l.column)
 	case '$':
 		tok = newToken(TOKEN_DOLLAR, l.ch, l.line, l.column)
-	case '"':
-		tok.Type = TOKEN_STRING
-		tok.Literal = l.readString()
+	case '"', '\'':
+		// String readers consume their closing quote themselves and stop
+		// before the offending character on error
+		if l.peekChar() == l.ch && l.peekCharAt(1) == l.ch {
+			l.readTripleQuotedString(&tok)
+		} else {
+			l.readString(&tok)
+		}
+		return tok
+	case '`':
+		l.readRawString(&tok)
+		return tok
 
 	case 0:
 		tok.Literal = ""
@@ -335,10 +500,17 @@ func (l *Lexer) NextToken() Token {
 	return tok
 }
 
-func newToken(tokenType TokenType, ch byte, line, column int) Token {
+func newToken(tokenType TokenType, ch rune, line, column int) Token {
 	return Token{Type: tokenType, Literal: string(ch), Line: line, Column: column}
 }
 
+// newTwoCharToken consumes the current and next character as one token.
+func (l *Lexer) newTwoCharToken(tokenType TokenType) Token {
+	ch, column := l.ch, l.column
+	l.readChar()
+	return Token{Type: tokenType, Literal: string(ch) + string(l.ch), Line: l.line, Column: column}
+}
+
 func (l *Lexer) readIdentifier() string {
 	position := l.position
 	for isLetter(l.ch) || isDigit(l.ch) || l.ch == '_' {
@@ -366,15 +538,255 @@ func (l *Lexer) readNumber() (string, bool) {
 	return l.input[position:l.position], isFloat
 }
 
-func (l *Lexer) readString() string {
-	position := l.position + 1
-	for {
+// peekCharAt looks n characters past the next one without advancing
+func (l *Lexer) peekCharAt(n int) rune {
+	for pos := l.readPosition; pos < len(l.input); n-- {
+		ch, width := utf8.DecodeRuneInString(l.input[pos:])
+		if n == 0 {
+			return ch
+		}
+		pos += width
+	}
+	return 0
+}
+
+// readStringChar advances one character inside a string literal, keeping
+// line and column right when the literal spans several lines.
+func (l *Lexer) readStringChar() {
+	if l.ch == '\n' {
+		l.line++
+		l.column = 0
+	}
+	l.readChar()
+}
+
+// readString reads a "..." or '...' literal, decoding escapes. Raw line
+// breaks are kept, as they always were; only end of input is an error.
+func (l *Lexer) readString(tok *Token) {
+	quote := l.ch
+	start := l.position
+	var str stringBuilder
+	l.readChar()
+	for l.ch != quote {
+		if l.ch == 0 {
+			tok.Type, tok.Literal = TOKEN_ERROR, "string ora ditutup"
+			return
+		}
+		if msg := l.readStringContent(&str, quote == '"'); msg != "" {
+			tok.Type, tok.Literal = TOKEN_ERROR, msg
+			return
+		}
+	}
+	l.readChar()
+	str.finish(tok, l.input[start:l.position])
+}
+
+// readTripleQuotedString reads a literal opened by three double or single quotes,
+// which may span lines. A newline right after the opening quotes is dropped.
+func (l *Lexer) readTripleQuotedString(tok *Token) {
+	quote := l.ch
+	start := l.position
+	l.readChar()
+	l.readChar()
+	l.readChar()
+	if l.ch == '\r' && l.peekChar() == '\n' {
 		l.readChar()
-		if l.ch == '"' || l.ch == 0 {
+	}
+	if l.ch == '\n' {
+		l.readStringChar()
+	}
+
+	var str stringBuilder
+	for !(l.ch == quote && l.peekChar() == quote && l.peekCharAt(1) == quote) {
+		if l.ch == 0 {
+			tok.Type, tok.Literal = TOKEN_ERROR, "string ora ditutup"
+			return
+		}
+		if msg := l.readStringContent(&str, quote == '"'); msg != "" {
+			tok.Type, tok.Literal = TOKEN_ERROR, msg
+			return
+		}
+	}
+	l.readChar()
+	l.readChar()
+	l.readChar()
+	str.finish(tok, l.input[start:l.position])
+}
+
+// StringPart is one piece of an interpolated string: literal text, or the
+// source of an embedded expression along with where that source starts.
+type StringPart struct {
+	Text   string
+	IsExpr bool
+	Line   int
+	Column int
+}
+
+// stringBuilder collects the decoded text and interpolated parts of a string.
+type stringBuilder struct {
+	text  strings.Builder
+	parts []StringPart
+}
+
+func (b *stringBuilder) flush() {
+	if b.text.Len() > 0 {
+		b.parts = append(b.parts, StringPart{Text: b.text.String()})
+		b.text.Reset()
+	}
+}
+
+// finish fills in tok as a plain TOKEN_STRING, or as TOKEN_INTERPOLATED with
+// the raw source as its literal when the string embeds expressions.
+func (b *stringBuilder) finish(tok *Token, source string) {
+	if b.parts == nil {
+		tok.Type, tok.Literal = TOKEN_STRING, b.text.String()
+		return
+	}
+	b.flush()
+	tok.Type, tok.Literal, tok.Parts = TOKEN_INTERPOLATED, source, b.parts
+}
+
+// readStringContent consumes the next character, escape sequence or, when
+// interpolate is set, embedded $var / {$expr} segment of a string body.
+func (l *Lexer) readStringContent(b *stringBuilder, interpolate bool) string {
+	switch {
+	case l.ch == '\\':
+		if msg := l.readEscape(&b.text); msg != "" {
+			return msg
+		}
+	case interpolate && l.ch == '$' && isLetter(l.peekChar()):
+		b.flush()
+		return l.readVariableSegment(b)
+	case interpolate && l.ch == '{' && l.peekChar() == '$':
+		b.flush()
+		return l.readExpressionSegment(b)
+	default:
+		// Copy the source bytes so invalid UTF-8 survives untouched
+		b.text.WriteString(l.input[l.position:l.readPosition])
+	}
+	l.readStringChar()
+	return ""
+}
+
+// readVariableSegment reads $name followed by any [index] accessors, so
+// "$row[0]" interpolates as a whole. Only $this takes .field accessors too:
+// elsewhere a dot is text, as in "$name.wlf"; use {$user.name} for those.
+func (l *Lexer) readVariableSegment(b *stringBuilder) string {
+	part := StringPart{IsExpr: true, Line: l.line, Column: l.column}
+	start := l.position
+	l.readChar() // consume '$'
+	fields := l.readIdentifier() == "this"
+	for {
+		if fields && l.ch == '.' && isLetter(l.peekChar()) {
+			l.readChar()
+			l.readIdentifier()
+		} else if l.ch == '[' {
+			if msg := l.skipBalanced(); msg != "" {
+				return msg
+			}
+		} else {
 			break
 		}
 	}
-	return l.input[position:l.position]
+	part.Text = l.input[start:l.position]
+	b.parts = append(b.parts, part)
+	return ""
+}
+
+// readExpressionSegment reads a {$expr} segment. The braces may hold any
+// expression as long as it starts with a variable.
+func (l *Lexer) readExpressionSegment(b *stringBuilder) string {
+	part := StringPart{IsExpr: true, Line: l.line, Column: l.column + 1}
+	start := l.position + 1
+	if msg := l.skipBalanced(); msg != "" {
+		return msg
+	}
+	part.Text = l.input[start : l.position-1]
+	b.parts = append(b.parts, part)
+	return ""
+}
+
+// skipBalanced consumes from the current opening bracket through its
+// matching close, stepping over nested brackets and quoted strings.
+func (l *Lexer) skipBalanced() string {
+	depth := 0
+	for {
+		switch l.ch {
+		case 0:
+			return "interpolasi ora ditutup"
+		case '{', '[', '(':
+			depth++
+		case '}', ']', ')':
+			depth--
+		case '"', '\'', '`':
+			quote := l.ch
+			l.readStringChar()
+			for l.ch != quote {
+				if l.ch == 0 {
+					return "interpolasi ora ditutup"
+				}
+				if l.ch == '\\' {
+					l.readChar()
+				}
+				l.readStringChar()
+			}
+		}
+		l.readStringChar()
+		if depth == 0 {
+			return ""
+		}
+	}
+}
+
+// readRawString reads a `...` literal verbatim: no escapes, newlines allowed.
+func (l *Lexer) readRawString(tok *Token) {
+	l.readChar()
+	position := l.position
+	for l.ch != '`' {
+		if l.ch == 0 {
+			tok.Type, tok.Literal = TOKEN_ERROR, "string ora ditutup"
+			return
+		}
+		l.readStringChar()
+	}
+	literal := l.input[position:l.position]
+	l.readChar()
+	tok.Type, tok.Literal = TOKEN_STRING, strings.ReplaceAll(literal, "\r\n", "\n")
+}
+
+// readEscape decodes the escape sequence starting at the current backslash
+// into out and leaves the lexer on its last character. Escapes follow Go:
+// \n \t \r \\ \" \' \` \$ \{ \xHH \uHHHH \UHHHHHHHH and friends. It returns an
+// error message for an unknown or malformed sequence.
+func (l *Lexer) readEscape(out *strings.Builder) string {
+	l.readChar()
+	switch l.ch {
+	case '"', '\'', '`', '$', '{':
+		out.WriteRune(l.ch)
+		return ""
+	case 0:
+		return "string ora ditutup"
+	}
+
+	// Hand strconv the sequence plus enough input for the longest escape
+	end := l.position + 9
+	if end > len(l.input) {
+		end = len(l.input)
+	}
+	seq := "\\" + l.input[l.position:end]
+	value, multibyte, tail, err := strconv.UnquoteChar(seq, 0)
+	if err != nil {
+		return fmt.Sprintf("escape sequence ora valid: \\%c", l.ch)
+	}
+	if multibyte {
+		out.WriteRune(value)
+	} else {
+		out.WriteByte(byte(value))
+	}
+	for i := len(seq) - len(tail); i > 2; i-- {
+		l.readChar()
+	}
+	return ""
 }
 
 func (l *Lexer) skipWhitespace() {
@@ -383,17 +795,45 @@ func (l *Lexer) skipWhitespace() {
 	}
 }
 
-func (l *Lexer) skipComment() {
+// readComment consumes a // line comment, /* block */ comment or /// doc
+// comment starting at tok. Doc comments are always returned so the parser
+// can attach them; other comments only in comment-preserving mode.
+func (l *Lexer) readComment(tok Token) (Token, bool) {
+	start := l.position
+	if l.peekChar() == '*' {
+		l.readChar()
+		l.readChar()
+		for !(l.ch == '*' && l.peekChar() == '/') {
+			if l.ch == 0 {
+				tok.Type, tok.Literal = TOKEN_ERROR, "komentar /* ora ditutup"
+				return tok, true
+			}
+			l.readStringChar()
+		}
+		l.readChar()
+		l.readChar()
+		tok.Type, tok.Literal = TOKEN_COMMENT, l.input[start:l.position]
+		return tok, l.preserveComments
+	}
+
+	doc := l.peekCharAt(1) == '/' && l.peekCharAt(2) != '/'
 	for l.ch != '\n' && l.ch != 0 {
 		l.readChar()
 	}
+	tok.Literal = strings.TrimRight(l.input[start:l.position], "\r")
+	if doc {
+		tok.Type = TOKEN_DOC_COMMENT
+		return tok, true
+	}
+	tok.Type = TOKEN_COMMENT
+	return tok, l.preserveComments
 }
 
-func isLetter(ch byte) bool {
-	return unicode.IsLetter(rune(ch)) || ch == '_'
+func isLetter(ch rune) bool {
+	return unicode.IsLetter(ch) || ch == '_'
 }
 
-func isDigit(ch byte) bool {
+func isDigit(ch rune) bool {
 	return '0' <= ch && ch <= '9'
 }
 
@@ -411,6 +851,14 @@ func TokenTypeString(t TokenType) string {
 		return "EOF"
 	case TOKEN_ILLEGAL:
 		return "ILLEGAL"
+	case TOKEN_ERROR:
+		return "ERROR"
+	case TOKEN_COMMENT:
+		return "COMMENT"
+	case TOKEN_DOC_COMMENT:
+		return "DOC_COMMENT"
+	case TOKEN_INTERPOLATED:
+		return "INTERPOLATED"
 	case TOKEN_IDENT:
 		return "IDENT"
 	case TOKEN_INT:
@@ -435,6 +883,22 @@ func TokenTypeString(t TokenType) string {
 		return "/"
 	case TOKEN_PERCENT:
 		return "%"
+	case TOKEN_POWER:
+		return "**"
+	case TOKEN_PLUS_ASSIGN:
+		return "+="
+	case TOKEN_MINUS_ASSIGN:
+		return "-="
+	case TOKEN_ASTERISK_ASSIGN:
+		return "*="
+	case TOKEN_SLASH_ASSIGN:
+		return "/="
+	case TOKEN_PERCENT_ASSIGN:
+		return "%="
+	case TOKEN_INCREMENT:
+		return "++"
+	case TOKEN_DECREMENT:
+		return "--"
 	case TOKEN_EQ:
 		return "=="
 	case TOKEN_NEQ:
@@ -467,6 +931,12 @@ func TokenTypeString(t TokenType) string {
 		return "HOWL"
 	case TOKEN_SUMMON:
 		return "SUMMON"
+	case TOKEN_IN:
+		return "IN"
+	case TOKEN_RANGE:
+		return "RANGE"
+	case TOKEN_NIL:
+		return "NIL"
 	case TOKEN_COMMA:
 		return ","
 	case TOKEN_COLON:
@@ -497,8 +967,48 @@ func TokenTypeString(t TokenType) string {
 		return "DEDENT"
 	case TOKEN_MOLD:
 		return "MOLD"
+	case TOKEN_BREAK:
+		return "BREAK"
+	case TOKEN_CONTINUE:
+		return "CONTINUE"
+	case TOKEN_TRY:
+		return "TRY"
+	case TOKEN_CATCH:
+		return "CATCH"
+	case TOKEN_FINALLY:
+		return "FINALLY"
+	case TOKEN_THROW:
+		return "THROW"
 	case TOKEN_DOT:
 		return "."
+	case TOKEN_ELLIPSIS:
+		return "..."
+	case TOKEN_MATCH:
+		return "MATCH"
+	case TOKEN_ARROW:
+		return "=>"
+	case TOKEN_LOCAL:
+		return "LOCAL"
+	case TOKEN_GLOBAL:
+		return "GLOBAL"
+	case TOKEN_SUPER:
+		return "SUPER"
+	case TOKEN_STATIC:
+		return "STATIC"
+	case TOKEN_CONST:
+		return "CONST"
+	case TOKEN_INTERFACE:
+		return "INTERFACE"
+	case TOKEN_TRAIT:
+		return "TRAIT"
+	case TOKEN_IMPLEMENTS:
+		return "IMPLEMENTS"
+	case TOKEN_USE:
+		return "USE"
+	case TOKEN_EXPORT:
+		return "EXPORT"
+	case TOKEN_AS:
+		return "AS"
 	default:
 		return "UNKNOWN"
 	}
@@ -0,0 +1,118 @@
+package lexer
+
+import (
+	"strings"
+	"testing"
+)
+
+// tokenTest is a source snippet and the type and literal of its first token.
+type tokenTest struct {
+	input   string
+	typ     TokenType
+	literal string
+}
+
+func runTokenTests(t *testing.T, tests []tokenTest) {
+	t.Helper()
+	for _, tt := range tests {
+		tok := New(tt.input).NextToken()
+		if tok.Type != tt.typ || tok.Literal != tt.literal {
+			t.Errorf("%q\n got: %s %q\nwant: %s %q", tt.input,
+				TokenTypeString(tok.Type), tok.Literal, TokenTypeString(tt.typ), tt.literal)
+		}
+	}
+}
+
+func TestStrings(t *testing.T) {
+	runTokenTests(t, []tokenTest{
+		{`"a\tb\n"`, TOKEN_STRING, "a\tb\n"},
+		{`'it\'s'`, TOKEN_STRING, "it's"},
+		{"`C:\\wolf\\n`", TOKEN_STRING, `C:\wolf\n`},
+		{"\"\"\"\n    SELECT 1\n\"\"\"", TOKEN_STRING, "    SELECT 1\n"},
+		// Plain quotes may still span lines
+		{"\"CREATE TABLE t (\n    id INTEGER\n)\"", TOKEN_STRING, "CREATE TABLE t (\n    id INTEGER\n)"},
+		{"'siji\nloro'", TOKEN_STRING, "siji\nloro"},
+		{`"ora ditutup`, TOKEN_ERROR, "string ora ditutup"},
+		{"\"ora\nditutup", TOKEN_ERROR, "string ora ditutup"},
+	})
+}
+
+func TestMultiLineStringPositions(t *testing.T) {
+	l := New("$a = \"x\ny\"\n$b")
+	var last Token
+	for tok := l.NextToken(); tok.Type != TOKEN_EOF; tok = l.NextToken() {
+		last = tok
+	}
+	if last.Literal != "b" || last.Line != 3 || last.Column != 2 {
+		t.Errorf("token after a multi-line string: got %q at %d:%d, want \"b\" at 3:2", last.Literal, last.Line, last.Column)
+	}
+}
+
+func TestInterpolationParts(t *testing.T) {
+	tests := []struct {
+		input string
+		parts []string // text as is, expressions wrapped in {}
+	}{
+		{`"Halo $jeneng!"`, []string{"Halo ", "{$jeneng}", "!"}},
+		{`"rp$total,-"`, []string{"rp", "{$total}", ",-"}},
+		{`"{$a + 1} lan $b[0]"`, []string{"{$a + 1}", " lan ", "{$b[0]}"}},
+		{`"\{$x}"`, []string{"{", "{$x}", "}"}},
+	}
+	for _, tt := range tests {
+		tok := New(tt.input).NextToken()
+		if tok.Type != TOKEN_INTERPOLATED {
+			t.Errorf("%s: got %s %q, want INTERPOLATED", tt.input, TokenTypeString(tok.Type), tok.Literal)
+			continue
+		}
+		got := []string{}
+		for _, part := range tok.Parts {
+			if part.IsExpr {
+				got = append(got, "{"+part.Text+"}")
+			} else {
+				got = append(got, part.Text)
+			}
+		}
+		if strings.Join(got, "|") != strings.Join(tt.parts, "|") {
+			t.Errorf("%s\n got: %q\nwant: %q", tt.input, got, tt.parts)
+		}
+	}
+
+	// A $ not followed by a letter, or escaped, stays text
+	runTokenTests(t, []tokenTest{
+		{`"$5"`, TOKEN_STRING, "$5"},
+		{`"US$ 10"`, TOKEN_STRING, "US$ 10"},
+		{`"rego\$total"`, TOKEN_STRING, "rego$total"},
+		{`"\$jeneng"`, TOKEN_STRING, "$jeneng"},
+		{`'costs $total'`, TOKEN_STRING, "costs $total"},
+		{"`costs $total`", TOKEN_STRING, "costs $total"},
+		{`"{kurung}"`, TOKEN_STRING, "{kurung}"},
+	})
+}
+
+func TestCommentTokens(t *testing.T) {
+	input := "// judul\n$a = 1 /* tengah */ + 2\n/// doc\n$b"
+	types := func(l *Lexer) []string {
+		got := []string{}
+		for tok := l.NextToken(); tok.Type != TOKEN_EOF; tok = l.NextToken() {
+			switch tok.Type {
+			case TOKEN_COMMENT, TOKEN_DOC_COMMENT:
+				got = append(got, TokenTypeString(tok.Type)+" "+tok.Literal)
+			default:
+				got = append(got, TokenTypeString(tok.Type))
+			}
+		}
+		return got
+	}
+
+	// By default only doc comments come through
+	if got := strings.Join(types(New(input)), ", "); got != "NEWLINE, $, IDENT, =, INT, +, INT, NEWLINE, DOC_COMMENT /// doc, NEWLINE, $, IDENT" {
+		t.Errorf("default mode:\n got: %s", got)
+	}
+
+	l := New(input)
+	l.PreserveComments()
+	want := "COMMENT // judul, NEWLINE, $, IDENT, =, INT, COMMENT /* tengah */, +, INT, NEWLINE, DOC_COMMENT /// doc, NEWLINE, $, IDENT"
+	if got := strings.Join(types(l), ", "); got != want {
+		t.Errorf("comment-preserving mode:\n got: %s\nwant: %s", got, want)
+	}
+}
@@ -52,6 +52,7 @@ func printUsage() {
 	fmt.Println("  wlf init <project-name>       Create new Wolf404 project")
 	fmt.Println("  wlf gas <file.wlf>            Run Wolf404 file in dev mode")
 	fmt.Println("  wlf gas server                Start the Wolf404 server")
+	fmt.Println("  wlf gas --ketat <file.wlf>    Run with out-of-range indexing as an error")
 	fmt.Println("  wlf gawe:model <Name>         Generate Model and Migration")
 	fmt.Println("  wlf gawe:controller <Name>    Generate Controller")
 	fmt.Println("  wlf gawe:middleware <Name>    Generate Middleware")
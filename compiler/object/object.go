@@ -2,6 +2,8 @@ package object
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 )
 
 // ObjectType represents the type of object
@@ -9,10 +11,13 @@ type ObjectType string
 
 const (
 	INTEGER_OBJ      = "INTEGER"
+	FLOAT_OBJ        = "FLOAT"
 	BOOLEAN_OBJ      = "BOOLEAN"
 	STRING_OBJ       = "STRING"
 	NULL_OBJ         = "NULL"
 	RETURN_VALUE_OBJ = "RETURN_VALUE"
+	BREAK_OBJ        = "BREAK"
+	CONTINUE_OBJ     = "CONTINUE"
 	ERROR_OBJ        = "ERROR"
 	FUNCTION_OBJ     = "FUNCTION"
 )
@@ -30,6 +35,20 @@ type Integer struct {
 func (i *Integer) Type() ObjectType { return INTEGER_OBJ }
 func (i *Integer) Inspect() string  { return fmt.Sprintf("%d", i.Value) }
 
+// Float
+type Float struct {
+	Value float64
+}
+
+func (f *Float) Type() ObjectType { return FLOAT_OBJ }
+func (f *Float) Inspect() string {
+	s := strconv.FormatFloat(f.Value, 'f', -1, 64)
+	if !strings.ContainsAny(s, ".eEnN") {
+		s += ".0"
+	}
+	return s
+}
+
 // Boolean
 type Boolean struct {
 	Value bool
@@ -60,18 +79,66 @@ type ReturnValue struct {
 func (rv *ReturnValue) Type() ObjectType { return RETURN_VALUE_OBJ }
 func (rv *ReturnValue) Inspect() string  { return rv.Value.Inspect() }
 
+// Break and Continue are loop control signals, propagated like ReturnValue
+type Break struct{}
+
+func (b *Break) Type() ObjectType { return BREAK_OBJ }
+func (b *Break) Inspect() string  { return "break" }
+
+type Continue struct{}
+
+func (c *Continue) Type() ObjectType { return CONTINUE_OBJ }
+func (c *Continue) Inspect() string  { return "continue" }
+
+// SourcePos is a location in a Wolf404 source file
+type SourcePos struct {
+	File   string
+	Line   int
+	Column int
+}
+
+func (p SourcePos) String() string {
+	file := p.File
+	if file == "" {
+		file = "<input>"
+	}
+	return fmt.Sprintf("%s:%d:%d", file, p.Line, p.Column)
+}
+
+// StackFrame is one function an error unwound through, and where in it
+type StackFrame struct {
+	Function string
+	Pos      SourcePos
+}
+
 // Error
 type Error struct {
+	Kind    string // e.g. "Error", "DbError" or a kind given to uncalno
 	Message string
+	Value   Object       // the thrown value for uncalno, nil for runtime errors
+	Pos     SourcePos    // where the error was raised
+	Stack   []StackFrame // Wolf404 call frames, innermost first
+
+	// FramePos is the position reached in the frame currently being
+	// unwound; nil until the innermost enclosing node claims it.
+	FramePos *SourcePos
+
+	// Caught is the value a cekel bound for this error, so that uncalno
+	// of that same value rethrows the error unchanged.
+	Caught Object
 }
 
 func (e *Error) Type() ObjectType { return ERROR_OBJ }
 func (e *Error) Inspect() string  { return "ERROR: " + e.Message }
 
 // Environment (Scope)
+//
+// Every function call gets its own environment enclosing the one the function
+// was defined in; the outermost environment is the module (global) scope.
 type Environment struct {
-	store map[string]Object
-	outer *Environment
+	store   map[string]Object
+	outer   *Environment
+	globals map[string]bool // names declared jagad (global) in this scope
 }
 
 func NewEnvironment() *Environment {
@@ -86,14 +153,59 @@ func NewEnclosedEnvironment(outer *Environment) *Environment {
 }
 
 func (e *Environment) Get(name string) (Object, bool) {
-	obj, ok := e.store[name]
-	if !ok && e.outer != nil {
-		obj, ok = e.outer.Get(name)
+	for env := e; env != nil; env = env.outer {
+		if env.globals[name] {
+			obj, ok := env.root().store[name]
+			return obj, ok
+		}
+		if obj, ok := env.store[name]; ok {
+			return obj, true
+		}
+	}
+	return nil, false
+}
+
+// Assign updates the nearest binding of name in this or an enclosing
+// scope, module scope included, so closures can change variables they
+// captured. When there is no binding yet, name is defined in e.
+func (e *Environment) Assign(name string, val Object) Object {
+	for env := e; env != nil; env = env.outer {
+		if env.globals[name] {
+			env.root().store[name] = val
+			return val
+		}
+		if _, ok := env.store[name]; ok {
+			env.store[name] = val
+			return val
+		}
+	}
+	e.store[name] = val
+	return val
+}
+
+// DeclareGlobal makes name refer to the module scope binding for the rest
+// of this scope.
+func (e *Environment) DeclareGlobal(name string) {
+	if e.outer == nil {
+		return
+	}
+	if e.globals == nil {
+		e.globals = make(map[string]bool)
+	}
+	e.globals[name] = true
+	delete(e.store, name)
+}
+
+func (e *Environment) root() *Environment {
+	for e.outer != nil {
+		e = e.outer
 	}
-	return obj, ok
+	return e
 }
 
+// Set defines name in this scope, shadowing any outer binding.
 func (e *Environment) Set(name string, val Object) Object {
+	delete(e.globals, name)
 	e.store[name] = val
 	return val
 }
@@ -8,9 +8,11 @@ import (
 
 // Function Object
 type Function struct {
-	Parameters []*ast.Identifier
+	Name       string // empty for anonymous garap
+	Parameters []*ast.Parameter
 	Body       *ast.BlockStatement
 	Env        *Environment
+	Owner      *Class // class the method was defined in; nil for plain functions
 }
 
 func (f *Function) Type() ObjectType { return FUNCTION_OBJ }
@@ -1,15 +1,28 @@
 package object
 
+import "wolf404/compiler/ast"
+
 const (
 	CLASS_OBJ        = "CLASS"
 	INSTANCE_OBJ     = "INSTANCE"
 	BOUND_METHOD_OBJ = "BOUND_METHOD"
+	SUPER_OBJ        = "SUPER"
+	CLASS_METHOD_OBJ = "CLASS_METHOD"
+	INTERFACE_OBJ    = "INTERFACE"
+	TRAIT_OBJ        = "TRAIT"
 )
 
 type Class struct {
 	Name    string
 	Super   *Class
 	Methods map[string]*Function
+
+	// Class-level members, reached as Class.name and inherited by subclasses
+	StaticMethods map[string]*Function
+	Properties    map[string]Object // statis $name = value
+	Constants     map[string]Object // tetep NAME = value
+
+	Interfaces []*Interface // kontrak listed after netepi
 }
 
 func (c *Class) Type() ObjectType { return CLASS_OBJ }
@@ -36,3 +49,56 @@ func (bm *BoundMethod) Type() ObjectType { return BOUND_METHOD_OBJ }
 func (bm *BoundMethod) Inspect() string {
 	return "bound method " + bm.Method.Inspect()
 }
+
+// Super is what induk evaluates to inside a method: the receiver, seen
+// through the parent of the class that defined the running method. Instance
+// is nil inside static methods, where Receiver is the class called on.
+type Super struct {
+	Instance *Instance
+	Receiver *Class
+	Class    *Class
+}
+
+func (s *Super) Type() ObjectType { return SUPER_OBJ }
+func (s *Super) Inspect() string {
+	if s.Instance != nil {
+		return "super of " + s.Instance.Class.Name
+	}
+	return "super of " + s.Receiver.Name
+}
+
+// ClassMethod is a static method looked up through a class. Class is the
+// class it was called on, which the method sees as $this.
+type ClassMethod struct {
+	Method *Function
+	Class  *Class
+}
+
+func (cm *ClassMethod) Type() ObjectType { return CLASS_METHOD_OBJ }
+func (cm *ClassMethod) Inspect() string {
+	return "static method " + cm.Class.Name + "." + cm.Method.Name
+}
+
+// Interface is a kontrak: method signatures a class must define. Methods
+// lists only its own signatures; Parents holds the kontrak it extends.
+type Interface struct {
+	Name    string
+	Parents []*Interface
+	Methods []*ast.InterfaceMethod
+}
+
+func (i *Interface) Type() ObjectType { return INTERFACE_OBJ }
+func (i *Interface) Inspect() string {
+	return "kontrak " + i.Name
+}
+
+// Trait is a sifat: methods copied into every class that mixes it in.
+type Trait struct {
+	Name    string
+	Methods map[string]*Function
+}
+
+func (t *Trait) Type() ObjectType { return TRAIT_OBJ }
+func (t *Trait) Inspect() string {
+	return "sifat " + t.Name
+}
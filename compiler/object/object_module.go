@@ -0,0 +1,34 @@
+package object
+
+const MODULE_OBJ = "MODULE"
+
+// Module is a loaded .wlf file. It is evaluated once in its own Env; only
+// the names in Exports are visible to importers, read live so later
+// assignments inside the module show through. Value is what a top-level
+// balekno returned, nil when the file has none.
+type Module struct {
+	Name    string // path as written in the import
+	Path    string // absolute path, the cache key
+	Env     *Environment
+	Exports []string
+	Value   Object
+
+	// Importer is the module whose evaluation loaded this one, nil for a
+	// module imported after every module above it finished loading.
+	Importer *Module
+}
+
+func (m *Module) Type() ObjectType { return MODULE_OBJ }
+func (m *Module) Inspect() string {
+	return "modul " + m.Name
+}
+
+// Export returns the exported binding name.
+func (m *Module) Export(name string) (Object, bool) {
+	for _, export := range m.Exports {
+		if export == name {
+			return m.Env.Get(name)
+		}
+	}
+	return nil, false
+}
@@ -1,7 +1,6 @@
 package parser
 
 import (
-	"fmt"
 	"strconv"
 	"wolf404/compiler/ast"
 	"wolf404/compiler/lexer"
@@ -12,30 +11,45 @@ const (
 	_ int = iota
 	LOWEST
 	ASSIGN      // =
+	OR          // utowo
+	AND         // lan
+	NOT         // ora X
 	EQUALS      // ==
 	LESSGREATER // > or <
 	SUM         // +
 	PRODUCT     // *
 	PREFIX      // -X or !X
+	POWER       // ** (binds tighter than unary minus: -2 ** 2 == -4)
 	CALL        // myFunction(X)
 	INDEX       // array[index]
 )
 
 var precedences = map[lexer.TokenType]int{
-	lexer.TOKEN_ASSIGN:   ASSIGN,
-	lexer.TOKEN_DOT:      INDEX,
-	lexer.TOKEN_LBRACKET: INDEX,
-	lexer.TOKEN_EQ:       EQUALS,
-	lexer.TOKEN_NEQ:      EQUALS,
-	lexer.TOKEN_LT:       LESSGREATER,
-	lexer.TOKEN_GT:       LESSGREATER,
-	lexer.TOKEN_LTE:      LESSGREATER,
-	lexer.TOKEN_GTE:      LESSGREATER,
-	lexer.TOKEN_PLUS:     SUM,
-	lexer.TOKEN_MINUS:    SUM,
-	lexer.TOKEN_SLASH:    PRODUCT,
-	lexer.TOKEN_ASTERISK: PRODUCT,
-	lexer.TOKEN_LPAREN:   CALL,
+	lexer.TOKEN_ASSIGN:          ASSIGN,
+	lexer.TOKEN_PLUS_ASSIGN:     ASSIGN,
+	lexer.TOKEN_MINUS_ASSIGN:    ASSIGN,
+	lexer.TOKEN_ASTERISK_ASSIGN: ASSIGN,
+	lexer.TOKEN_SLASH_ASSIGN:    ASSIGN,
+	lexer.TOKEN_PERCENT_ASSIGN:  ASSIGN,
+	lexer.TOKEN_INCREMENT:       CALL,
+	lexer.TOKEN_DECREMENT:       CALL,
+	lexer.TOKEN_DOT:             INDEX,
+	lexer.TOKEN_LBRACKET:        INDEX,
+	lexer.TOKEN_OR:              OR,
+	lexer.TOKEN_AND:             AND,
+	lexer.TOKEN_EQ:              EQUALS,
+	lexer.TOKEN_NEQ:             EQUALS,
+	lexer.TOKEN_LT:              LESSGREATER,
+	lexer.TOKEN_GT:              LESSGREATER,
+	lexer.TOKEN_LTE:             LESSGREATER,
+	lexer.TOKEN_GTE:             LESSGREATER,
+	lexer.TOKEN_PLUS:            SUM,
+	lexer.TOKEN_MINUS:           SUM,
+	lexer.TOKEN_SLASH:           PRODUCT,
+	lexer.TOKEN_ASTERISK:        PRODUCT,
+	lexer.TOKEN_PERCENT:         PRODUCT,
+	lexer.TOKEN_POWER:           POWER,
+	lexer.TOKEN_LPAREN:          CALL,
 }
 
 type (
@@ -44,8 +58,15 @@ type (
 )
 
 type Parser struct {
-	l      *lexer.Lexer
-	errors []string
+	l          *lexer.Lexer
+	errors     []*ParseError
+	warnings   []*ParseError
+	recovering bool // an error was reported in the current statement
+
+	matches []*ast.MatchExpression // cocokno checked once the file is parsed
+
+	doc     string // pending /// doc comment text
+	docLine int    // line of the last doc comment line
 
 	curToken  lexer.Token
 	peekToken lexer.Token
@@ -57,15 +78,21 @@ type Parser struct {
 func New(l *lexer.Lexer) *Parser {
 	p := &Parser{
 		l:      l,
-		errors: []string{},
+		errors: []*ParseError{},
 	}
 
 	p.prefixParseFns = make(map[lexer.TokenType]prefixParseFn)
 	p.registerPrefix(lexer.TOKEN_IDENT, p.parseIdentifier)
 	p.registerPrefix(lexer.TOKEN_INT, p.parseIntegerLiteral)
+	p.registerPrefix(lexer.TOKEN_FLOAT, p.parseFloatLiteral)
 	p.registerPrefix(lexer.TOKEN_STRING, p.parseStringLiteral)
+	p.registerPrefix(lexer.TOKEN_INTERPOLATED, p.parseInterpolatedString)
 	p.registerPrefix(lexer.TOKEN_TRUE, p.parseBoolean)
 	p.registerPrefix(lexer.TOKEN_FALSE, p.parseBoolean)
+	p.registerPrefix(lexer.TOKEN_NIL, p.parseNilLiteral)
+	p.registerPrefix(lexer.TOKEN_RANGE, p.parseRangeIdentifier)
+	p.registerPrefix(lexer.TOKEN_MINUS, p.parsePrefixExpression)
+	p.registerPrefix(lexer.TOKEN_NOT, p.parsePrefixExpression)
 	p.registerPrefix(lexer.TOKEN_LPAREN, p.parseGroupedExpression)
 	p.registerPrefix(lexer.TOKEN_DOLLAR, p.parseVariableExpression)
 	p.registerPrefix(lexer.TOKEN_LPAREN, p.parseGroupedExpression)
@@ -76,6 +103,8 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(lexer.TOKEN_LBRACKET, p.parseArrayLiteral)
 	p.registerPrefix(lexer.TOKEN_LBRACE, p.parseHashLiteral)
 	p.registerPrefix(lexer.TOKEN_HUNT, p.parseFunctionLiteral)
+	p.registerPrefix(lexer.TOKEN_MATCH, p.parseMatchExpression)
+	p.registerPrefix(lexer.TOKEN_SUPER, p.parseSuperExpression)
 	p.registerPrefix(lexer.TOKEN_DOLLAR, p.parseVariableUsage)
 
 	p.infixParseFns = make(map[lexer.TokenType]infixParseFn)
@@ -83,11 +112,24 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(lexer.TOKEN_MINUS, p.parseInfixExpression)
 	p.registerInfix(lexer.TOKEN_SLASH, p.parseInfixExpression)
 	p.registerInfix(lexer.TOKEN_ASTERISK, p.parseInfixExpression)
+	p.registerInfix(lexer.TOKEN_PERCENT, p.parseInfixExpression)
+	p.registerInfix(lexer.TOKEN_POWER, p.parseInfixExpression)
 	p.registerInfix(lexer.TOKEN_EQ, p.parseInfixExpression)
 	p.registerInfix(lexer.TOKEN_NEQ, p.parseInfixExpression)
 	p.registerInfix(lexer.TOKEN_LT, p.parseInfixExpression)
 	p.registerInfix(lexer.TOKEN_GT, p.parseInfixExpression)
+	p.registerInfix(lexer.TOKEN_LTE, p.parseInfixExpression)
+	p.registerInfix(lexer.TOKEN_GTE, p.parseInfixExpression)
+	p.registerInfix(lexer.TOKEN_AND, p.parseLogicalExpression)
+	p.registerInfix(lexer.TOKEN_OR, p.parseLogicalExpression)
 	p.registerInfix(lexer.TOKEN_ASSIGN, p.parseInfixExpression)
+	p.registerInfix(lexer.TOKEN_PLUS_ASSIGN, p.parseInfixExpression)
+	p.registerInfix(lexer.TOKEN_MINUS_ASSIGN, p.parseInfixExpression)
+	p.registerInfix(lexer.TOKEN_ASTERISK_ASSIGN, p.parseInfixExpression)
+	p.registerInfix(lexer.TOKEN_SLASH_ASSIGN, p.parseInfixExpression)
+	p.registerInfix(lexer.TOKEN_PERCENT_ASSIGN, p.parseInfixExpression)
+	p.registerInfix(lexer.TOKEN_INCREMENT, p.parseIncrementExpression)
+	p.registerInfix(lexer.TOKEN_DECREMENT, p.parseIncrementExpression)
 	p.registerInfix(lexer.TOKEN_DOT, p.parseInfixExpression)
 	p.registerInfix(lexer.TOKEN_LPAREN, p.parseCallExpression)
 	p.registerInfix(lexer.TOKEN_LBRACKET, p.parseIndexExpression)
@@ -103,35 +145,48 @@ func New(l *lexer.Lexer) *Parser {
 func (p *Parser) nextToken() {
 	p.curToken = p.peekToken
 	p.peekToken = p.l.NextToken()
-	
-	// Skip extra newlines if they appear where we expect statement starts, 
-	// but mostly we want to consume them as separators in loop.
-	// Actually, let's filter them out inside ParseProgram main loop or here?
-	// If we filter here, we lose line info somewhat, but easy for MVP.
-	// But let's keeping them and handle in ParseProgram.
+
+	// Comments never reach the grammar; doc comments are held for the
+	// declaration that follows them
+	for p.peekToken.Type == lexer.TOKEN_COMMENT || p.peekToken.Type == lexer.TOKEN_DOC_COMMENT {
+		if p.peekToken.Type == lexer.TOKEN_DOC_COMMENT {
+			p.collectDoc(p.peekToken)
+		}
+		p.peekToken = p.l.NextToken()
+	}
 }
 
-func (p *Parser) Errors() []string {
+func (p *Parser) Errors() []*ParseError {
 	return p.errors
 }
 
+// Warnings returns problems that do not stop the program from running.
+func (p *Parser) Warnings() []*ParseError {
+	return p.warnings
+}
+
 func (p *Parser) ParseProgram() *ast.Program {
 	program := &ast.Program{}
 	program.Statements = []ast.Statement{}
 
-	for p.curToken.Type != lexer.TOKEN_EOF {
+	for p.curToken.Type != lexer.TOKEN_EOF && !p.tooManyErrors() {
 		if p.curToken.Type == lexer.TOKEN_NEWLINE {
 			p.nextToken()
 			continue
 		}
 		
 		stmt := p.parseStatement()
-		if stmt != nil {
+		// A statement that reported an error is incomplete; drop it
+		if stmt != nil && !p.recovering {
 			program.Statements = append(program.Statements, stmt)
 		}
+		if p.recovering {
+			p.synchronize()
+		}
 		p.nextToken()
 	}
 
+	p.checkMatches(program)
 	return program
 }
 
@@ -147,6 +202,30 @@ func (p *Parser) parseStatement() ast.Statement {
 		return p.parseTrackStatement()
 	case lexer.TOKEN_MOLD:
 		return p.parseClassStatement()
+	case lexer.TOKEN_BREAK:
+		return &ast.BreakStatement{Token: p.curToken}
+	case lexer.TOKEN_CONTINUE:
+		return &ast.ContinueStatement{Token: p.curToken}
+	case lexer.TOKEN_TRY:
+		return p.parseTryStatement()
+	case lexer.TOKEN_THROW:
+		return p.parseThrowStatement()
+	case lexer.TOKEN_LOCAL:
+		return p.parseLetStatement()
+	case lexer.TOKEN_GLOBAL:
+		return p.parseGlobalStatement()
+	case lexer.TOKEN_STATIC:
+		return p.parseStaticStatement()
+	case lexer.TOKEN_CONST:
+		return p.parseConstStatement()
+	case lexer.TOKEN_INTERFACE:
+		return p.parseInterfaceStatement()
+	case lexer.TOKEN_TRAIT:
+		return p.parseTraitStatement()
+	case lexer.TOKEN_USE:
+		return p.parseUseStatement()
+	case lexer.TOKEN_EXPORT:
+		return p.parseExportStatement()
 	case lexer.TOKEN_NEWLINE, lexer.TOKEN_INDENT, lexer.TOKEN_DEDENT:
 		return nil
 	default:
@@ -154,20 +233,19 @@ func (p *Parser) parseStatement() ast.Statement {
 	}
 }
 
+// parseLetStatement parses lokal $name or lokal $name = value.
 func (p *Parser) parseLetStatement() *ast.LetStatement {
 	stmt := &ast.LetStatement{Token: p.curToken}
 
-	// Expecting identifier after $
-	if !p.expectPeek(lexer.TOKEN_IDENT) {
+	if !p.expectPeek(lexer.TOKEN_DOLLAR) || !p.expectPeek(lexer.TOKEN_IDENT) {
 		return nil
 	}
-	
 	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 
-	if !p.expectPeek(lexer.TOKEN_ASSIGN) {
-		return nil
+	if p.peekToken.Type != lexer.TOKEN_ASSIGN {
+		return stmt
 	}
-
+	p.nextToken()
 	p.nextToken()
 
 	stmt.Value = p.parseExpression(LOWEST)
@@ -175,6 +253,25 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 	return stmt
 }
 
+// parseGlobalStatement parses jagad $a, $b.
+func (p *Parser) parseGlobalStatement() *ast.GlobalStatement {
+	stmt := &ast.GlobalStatement{Token: p.curToken}
+
+	for {
+		if !p.expectPeek(lexer.TOKEN_DOLLAR) || !p.expectPeek(lexer.TOKEN_IDENT) {
+			return nil
+		}
+		stmt.Names = append(stmt.Names, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+
+		if p.peekToken.Type != lexer.TOKEN_COMMA {
+			break
+		}
+		p.nextToken()
+	}
+
+	return stmt
+}
+
 func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	stmt := &ast.ReturnStatement{Token: p.curToken}
 
@@ -218,6 +315,12 @@ func (p *Parser) parseIdentifier() ast.Expression {
 	return &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 }
 
+// parseRangeIdentifier lets the deret/range keyword be called like the
+// builtin it names: deret(0, 10, 2).
+func (p *Parser) parseRangeIdentifier() ast.Expression {
+	return &ast.Identifier{Token: p.curToken, Value: "deret"}
+}
+
 func (p *Parser) parseVariableExpression() ast.Expression {
 	// For $var usage in expressions
 	// Current token is $
@@ -234,8 +337,20 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 
 	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
 	if err != nil {
-		msg := fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
+		p.errorAt(p.curToken, nil, "could not parse %q as integer", p.curToken.Literal)
+		return nil
+	}
+
+	lit.Value = value
+	return lit
+}
+
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	lit := &ast.FloatLiteral{Token: p.curToken}
+
+	value, err := strconv.ParseFloat(p.curToken.Literal, 64)
+	if err != nil {
+		p.errorAt(p.curToken, nil, "could not parse %q as float", p.curToken.Literal)
 		return nil
 	}
 
@@ -247,10 +362,58 @@ func (p *Parser) parseStringLiteral() ast.Expression {
 	return &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
 }
 
+// parseInterpolatedString parses each embedded segment with its own lexer,
+// positioned where the segment sits in the file so errors point inside the string.
+func (p *Parser) parseInterpolatedString() ast.Expression {
+	str := &ast.InterpolatedString{Token: p.curToken}
+
+	for _, part := range p.curToken.Parts {
+		if !part.IsExpr {
+			str.Parts = append(str.Parts, &ast.StringLiteral{Token: p.curToken, Value: part.Text})
+			continue
+		}
+
+		sub := New(lexer.NewAt(part.Text, p.curToken.File, part.Line, part.Column))
+		exp := sub.parseExpression(LOWEST)
+		if len(sub.errors) == 0 && sub.peekToken.Type != lexer.TOKEN_EOF {
+			sub.errorAt(sub.peekToken, nil, "unexpected %s in interpolation", describeToken(sub.peekToken))
+		}
+		for _, err := range sub.errors {
+			p.errorAt(err.Token, err.Expected, "%s", err.Message)
+		}
+		str.Parts = append(str.Parts, exp)
+	}
+
+	return str
+}
+
 func (p *Parser) parseBoolean() ast.Expression {
 	return &ast.Boolean{Token: p.curToken, Value: p.curToken.Type == lexer.TOKEN_TRUE}
 }
 
+func (p *Parser) parseNilLiteral() ast.Expression {
+	return &ast.NilLiteral{Token: p.curToken}
+}
+
+func (p *Parser) parsePrefixExpression() ast.Expression {
+	expression := &ast.PrefixExpression{Token: p.curToken, Operator: p.curToken.Literal}
+
+	// ora / not / ! all share one operator so the evaluator sees a single spelling.
+	// The word forms bind looser than comparisons: `ora $a > 0` is `ora ($a > 0)`.
+	precedence := PREFIX
+	if p.curToken.Type == lexer.TOKEN_NOT {
+		expression.Operator = "not"
+		if p.curToken.Literal != "!" {
+			precedence = NOT
+		}
+	}
+
+	p.nextToken()
+	expression.Right = p.parseExpression(precedence)
+
+	return expression
+}
+
 func (p *Parser) parseGroupedExpression() ast.Expression {
 	p.nextToken()
 
@@ -270,6 +433,44 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 		Left:     left,
 	}
 
+	precedence := p.curPrecedence()
+	// ** is right-associative: 2 ** 3 ** 2 == 2 ** (3 ** 2)
+	if p.curToken.Type == lexer.TOKEN_POWER {
+		precedence--
+	}
+	p.nextToken()
+	expression.Right = p.parseExpression(precedence)
+
+	return expression
+}
+
+// parseIncrementExpression desugars $i++ / $i-- into $i += 1 / $i -= 1.
+func (p *Parser) parseIncrementExpression(left ast.Expression) ast.Expression {
+	expression := &ast.InfixExpression{
+		Token:    p.curToken,
+		Operator: "+=",
+		Left:     left,
+	}
+	one := p.curToken
+	one.Type, one.Literal = lexer.TOKEN_INT, "1"
+	expression.Right = &ast.IntegerLiteral{Token: one, Value: 1}
+	if p.curToken.Type == lexer.TOKEN_DECREMENT {
+		expression.Operator = "-="
+	}
+	return expression
+}
+
+// parseLogicalExpression normalizes lan/and and utowo/or to "and"/"or".
+func (p *Parser) parseLogicalExpression(left ast.Expression) ast.Expression {
+	expression := &ast.InfixExpression{
+		Token:    p.curToken,
+		Operator: "and",
+		Left:     left,
+	}
+	if p.curToken.Type == lexer.TOKEN_OR {
+		expression.Operator = "or"
+	}
+
 	precedence := p.curPrecedence()
 	p.nextToken()
 	expression.Right = p.parseExpression(precedence)
@@ -291,13 +492,28 @@ func (p *Parser) parseCallArguments() []ast.Expression {
 		return args
 	}
 
-	p.nextToken()
-	args = append(args, p.parseExpression(LOWEST))
-
-	for p.peekToken.Type == lexer.TOKEN_COMMA {
+	named := false
+	for {
 		p.nextToken()
+		if p.curToken.Type == lexer.TOKEN_IDENT && p.peekToken.Type == lexer.TOKEN_COLON {
+			named = true
+			arg := &ast.NamedArgument{Token: p.curToken, Name: p.curToken.Literal}
+			p.nextToken() // consume name
+			p.nextToken() // consume ':'
+			arg.Value = p.parseExpression(LOWEST)
+			args = append(args, arg)
+		} else {
+			if named {
+				p.errorAt(p.curToken, nil, "argumen posisi ora oleh sawise argumen jeneng")
+				return nil
+			}
+			args = append(args, p.parseExpression(LOWEST))
+		}
+
+		if p.peekToken.Type != lexer.TOKEN_COMMA {
+			break
+		}
 		p.nextToken()
-		args = append(args, p.parseExpression(LOWEST))
 	}
 
 	if !p.expectPeek(lexer.TOKEN_RPAREN) {
@@ -334,9 +550,9 @@ func (p *Parser) expectPeek(t lexer.TokenType) bool {
 }
 
 func (p *Parser) peekError(t lexer.TokenType) {
-	msg := fmt.Sprintf("expected next token to be %s, got %s instead",
-		lexer.TokenTypeString(t), lexer.TokenTypeString(p.peekToken.Type))
-	p.errors = append(p.errors, msg)
+	expected := lexer.TokenTypeString(t)
+	p.errorAt(p.peekToken, []string{expected}, "expected next token to be %s, got %s instead",
+		expected, describeToken(p.peekToken))
 }
 
 func (p *Parser) registerPrefix(tokenType lexer.TokenType, fn prefixParseFn) {
@@ -348,6 +564,5 @@ func (p *Parser) registerInfix(tokenType lexer.TokenType, fn infixParseFn) {
 }
 
 func (p *Parser) noPrefixParseFnError(t lexer.TokenType) {
-	msg := fmt.Sprintf("no prefix parse function for %s found", lexer.TokenTypeString(t))
-	p.errors = append(p.errors, msg)
+	p.errorAt(p.curToken, []string{"expression"}, "unexpected %s, expected an expression", describeToken(p.curToken))
 }
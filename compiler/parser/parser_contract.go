@@ -0,0 +1,148 @@
+package parser
+
+import (
+	"wolf404/compiler/ast"
+	"wolf404/compiler/lexer"
+)
+
+// parseInterfaceStatement parses
+//
+//	kontrak Name : Parent, ...
+//	    garap method($param, ...)
+//
+// Method signatures have no body. A kontrak without a block requires nothing
+// beyond its parents.
+func (p *Parser) parseInterfaceStatement() *ast.InterfaceStatement {
+	stmt := &ast.InterfaceStatement{Token: p.curToken, Doc: p.takeDoc(p.curToken)}
+
+	if !p.expectPeek(lexer.TOKEN_IDENT) {
+		return nil
+	}
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if p.peekToken.Type == lexer.TOKEN_COLON {
+		p.nextToken()
+		if stmt.Parents = p.parseNameList(); stmt.Parents == nil {
+			return nil
+		}
+	}
+
+	if p.peekToken.Type == lexer.TOKEN_NEWLINE {
+		p.nextToken()
+	}
+	if p.peekToken.Type != lexer.TOKEN_INDENT {
+		return stmt
+	}
+	p.nextToken()
+	p.nextToken() // consume INDENT
+
+	for p.curToken.Type != lexer.TOKEN_DEDENT && p.curToken.Type != lexer.TOKEN_EOF {
+		if p.curToken.Type == lexer.TOKEN_NEWLINE {
+			p.nextToken()
+			continue
+		}
+
+		method := p.parseInterfaceMethod()
+		if method != nil {
+			stmt.Methods = append(stmt.Methods, method)
+		}
+		if p.recovering {
+			p.synchronize()
+		}
+
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseInterfaceMethod() *ast.InterfaceMethod {
+	if p.curToken.Type != lexer.TOKEN_HUNT {
+		p.errorAt(p.curToken, []string{"garap"}, "kontrak mung oleh isi garap jeneng(parameter), oleh %s", describeToken(p.curToken))
+		return nil
+	}
+	method := &ast.InterfaceMethod{Token: p.curToken}
+
+	if !p.expectPeek(lexer.TOKEN_IDENT) {
+		return nil
+	}
+	method.Name = p.curToken.Literal
+
+	if !p.expectPeek(lexer.TOKEN_LPAREN) {
+		return nil
+	}
+	if method.Parameters = p.parseFunctionParameters(); method.Parameters == nil {
+		return nil
+	}
+
+	if p.peekToken.Type == lexer.TOKEN_NEWLINE {
+		p.nextToken()
+	}
+	if p.peekToken.Type == lexer.TOKEN_INDENT {
+		p.errorAt(p.peekToken, nil, "method kontrak %s ora oleh nduwe isi", method.Name)
+		p.nextToken()
+		p.parseBlockStatement() // skip the body so it is reported once
+		return nil
+	}
+
+	return method
+}
+
+// parseTraitStatement parses sifat Name followed by a block of methods.
+func (p *Parser) parseTraitStatement() *ast.TraitStatement {
+	stmt := &ast.TraitStatement{Token: p.curToken, Doc: p.takeDoc(p.curToken)}
+
+	if !p.expectPeek(lexer.TOKEN_IDENT) {
+		return nil
+	}
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	p.skipToBlock()
+	stmt.Body = p.parseBlockStatement()
+
+	for _, s := range stmt.Body.Statements {
+		switch s := s.(type) {
+		case *ast.UseStatement:
+			continue
+		case *ast.ExpressionStatement:
+			if fn, ok := s.Expression.(*ast.FunctionLiteral); ok && fn.Name != "" {
+				continue
+			}
+		}
+		p.errorAt(s.Pos(), nil, "sifat %s mung oleh isi garap lan nyampur, oleh %s", stmt.Name.Value, describeToken(s.Pos()))
+		return nil
+	}
+
+	return stmt
+}
+
+// parseUseStatement parses nyampur Trait, ... inside a gerombolan or sifat.
+func (p *Parser) parseUseStatement() *ast.UseStatement {
+	stmt := &ast.UseStatement{Token: p.curToken}
+
+	if stmt.Traits = p.parseNameList(); stmt.Traits == nil {
+		return nil
+	}
+
+	return stmt
+}
+
+// parseNameList parses the comma separated names after the current token,
+// leaving curToken on the last one.
+func (p *Parser) parseNameList() []*ast.Identifier {
+	names := []*ast.Identifier{}
+
+	for {
+		if !p.expectPeek(lexer.TOKEN_IDENT) {
+			return nil
+		}
+		names = append(names, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+
+		if p.peekToken.Type != lexer.TOKEN_COMMA {
+			break
+		}
+		p.nextToken()
+	}
+
+	return names
+}
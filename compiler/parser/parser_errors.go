@@ -0,0 +1,144 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"wolf404/compiler/lexer"
+)
+
+// MaxErrors caps how many errors are reported for a single file. When one
+// more turns up, a "too many errors" note is added at it and the parser
+// stops, since later errors are rarely worth reading.
+const MaxErrors = 10
+
+// ParseError is a syntax error together with where it was found.
+type ParseError struct {
+	Token    lexer.Token // token the error points at
+	Message  string
+	Expected []string // token or construct names that would have been valid
+}
+
+// Position renders the error location as file:line:col.
+func (e *ParseError) Position() string {
+	file := e.Token.File
+	if file == "" {
+		file = "<input>"
+	}
+	if e.Token.Line == 0 {
+		return file
+	}
+	return fmt.Sprintf("%s:%d:%d", file, e.Token.Line, e.Token.Column)
+}
+
+func (e *ParseError) Error() string {
+	return e.Position() + ": " + e.Message
+}
+
+// Snippet returns the offending source line with a caret under the error
+// column, or "" when the line is not part of source.
+func (e *ParseError) Snippet(source string) string {
+	lines := strings.Split(source, "\n")
+	if e.Token.Line < 1 || e.Token.Line > len(lines) {
+		return ""
+	}
+	line := strings.TrimRight(lines[e.Token.Line-1], "\r")
+
+	// Columns count runes; keep tabs in the padding so the caret lines up
+	var pad strings.Builder
+	runes := []rune(line)
+	for i := 0; i < e.Token.Column-1; i++ {
+		if i < len(runes) && runes[i] == '\t' {
+			pad.WriteByte('\t')
+		} else {
+			pad.WriteByte(' ')
+		}
+	}
+	return line + "\n" + pad.String() + "^"
+}
+
+// FormatErrors renders errs one after another, each followed by its source
+// snippet.
+func FormatErrors(errs []*ParseError, source string) string {
+	var out strings.Builder
+	for _, err := range errs {
+		out.WriteString(err.Error())
+		out.WriteString("\n")
+		if snippet := err.Snippet(source); snippet != "" {
+			for _, line := range strings.Split(snippet, "\n") {
+				out.WriteString("    " + line + "\n")
+			}
+		}
+	}
+	return out.String()
+}
+
+// FormatWarnings renders warnings like FormatErrors, marking each one so it
+// is not mistaken for an error.
+func FormatWarnings(warnings []*ParseError, source string) string {
+	marked := make([]*ParseError, len(warnings))
+	for i, w := range warnings {
+		marked[i] = &ParseError{Token: w.Token, Message: "peringatan: " + w.Message}
+	}
+	return FormatErrors(marked, source)
+}
+
+// errorAt records an error at tok. Only the first error of a statement is
+// kept; the rest are usually fallout from it and are dropped until the
+// parser has synchronized on the next statement.
+func (p *Parser) errorAt(tok lexer.Token, expected []string, format string, args ...interface{}) {
+	if p.recovering || p.tooManyErrors() {
+		return
+	}
+	p.recovering = true
+
+	if len(p.errors) == MaxErrors {
+		p.errors = append(p.errors, &ParseError{Token: tok, Message: "too many errors"})
+		return
+	}
+	// A lexical error explains itself better than whatever tripped over it
+	if tok.Type == lexer.TOKEN_ERROR {
+		p.errors = append(p.errors, &ParseError{Token: tok, Message: tok.Literal})
+		return
+	}
+	p.errors = append(p.errors, &ParseError{
+		Token:    tok,
+		Message:  fmt.Sprintf(format, args...),
+		Expected: expected,
+	})
+}
+
+// warnAt records a warning at tok.
+func (p *Parser) warnAt(tok lexer.Token, format string, args ...interface{}) {
+	p.warnings = append(p.warnings, &ParseError{Token: tok, Message: fmt.Sprintf(format, args...)})
+}
+
+func (p *Parser) tooManyErrors() bool {
+	return len(p.errors) > MaxErrors
+}
+
+// synchronize skips the rest of a broken statement, leaving curToken on its
+// last token so the statement loop resumes at the next line.
+func (p *Parser) synchronize() {
+	for !isStatementEnd(p.curToken.Type) && !isStatementEnd(p.peekToken.Type) {
+		p.nextToken()
+	}
+	p.recovering = false
+}
+
+func isStatementEnd(t lexer.TokenType) bool {
+	return t == lexer.TOKEN_NEWLINE || t == lexer.TOKEN_DEDENT || t == lexer.TOKEN_EOF
+}
+
+// describeToken names a token for error messages, showing the literal for
+// identifiers and values.
+func describeToken(tok lexer.Token) string {
+	switch tok.Type {
+	case lexer.TOKEN_IDENT, lexer.TOKEN_INT, lexer.TOKEN_FLOAT:
+		return fmt.Sprintf("%s %q", lexer.TokenTypeString(tok.Type), tok.Literal)
+	case lexer.TOKEN_STRING, lexer.TOKEN_INTERPOLATED:
+		return "STRING"
+	case lexer.TOKEN_ILLEGAL:
+		return fmt.Sprintf("character %q", tok.Literal)
+	}
+	return lexer.TokenTypeString(tok.Type)
+}
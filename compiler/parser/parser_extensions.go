@@ -23,6 +23,16 @@ func (p *Parser) parseIfExpression() ast.Expression {
 
 	if p.peekToken.Type == lexer.TOKEN_MISSING {
 		p.nextToken() // consume 'missing'
+
+		// missing sniff <cond>: the rest of the chain is parsed as a nested if
+		if p.peekToken.Type == lexer.TOKEN_SNIFF {
+			p.nextToken()
+			if elseIf, ok := p.parseIfExpression().(*ast.IfExpression); ok {
+				expression.ElseIf = elseIf
+			}
+			return expression
+		}
+
 		if p.peekToken.Type == lexer.TOKEN_NEWLINE {
 			p.nextToken()
 		}
@@ -50,9 +60,15 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 			}
 
 			stmt := p.parseStatement()
-			if stmt != nil {
+			if export, ok := stmt.(*ast.ExportStatement); ok && export != nil {
+				p.errorAt(export.Token, nil, "ekspor mung iso neng tingkat paling njaba file")
+			}
+			if stmt != nil && !p.recovering {
 				block.Statements = append(block.Statements, stmt)
 			}
+			if p.recovering {
+				p.synchronize()
+			}
 			
 			p.nextToken()
 		}
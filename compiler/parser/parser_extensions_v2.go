@@ -80,7 +80,38 @@ func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 	exp := &ast.IndexExpression{Token: p.curToken, Left: left}
 
 	p.nextToken()
+	if p.curToken.Type == lexer.TOKEN_COLON {
+		return p.parseSliceExpression(exp.Token, left, nil)
+	}
 	exp.Index = p.parseExpression(LOWEST)
+	if p.peekToken.Type == lexer.TOKEN_COLON {
+		p.nextToken()
+		return p.parseSliceExpression(exp.Token, left, exp.Index)
+	}
+
+	if !p.expectPeek(lexer.TOKEN_RBRACKET) {
+		return nil
+	}
+
+	return exp
+}
+
+// parseSliceExpression parses the rest of [start:end:step] with curToken on
+// the first colon. Omitted parts stay nil.
+func (p *Parser) parseSliceExpression(tok lexer.Token, left, start ast.Expression) ast.Expression {
+	exp := &ast.SliceExpression{Token: tok, Left: left, Start: start}
+
+	if p.peekToken.Type != lexer.TOKEN_COLON && p.peekToken.Type != lexer.TOKEN_RBRACKET {
+		p.nextToken()
+		exp.End = p.parseExpression(LOWEST)
+	}
+	if p.peekToken.Type == lexer.TOKEN_COLON {
+		p.nextToken()
+		if p.peekToken.Type != lexer.TOKEN_RBRACKET {
+			p.nextToken()
+			exp.Step = p.parseExpression(LOWEST)
+		}
+	}
 
 	if !p.expectPeek(lexer.TOKEN_RBRACKET) {
 		return nil
@@ -15,7 +15,7 @@ func (p *Parser) parseVariableUsage() ast.Expression {
 }
 
 func (p *Parser) parseFunctionLiteral() ast.Expression {
-	lit := &ast.FunctionLiteral{Token: p.curToken}
+	lit := &ast.FunctionLiteral{Token: p.curToken, Doc: p.takeDoc(p.curToken)}
 
 	if p.peekToken.Type == lexer.TOKEN_IDENT {
 		p.nextToken()
@@ -42,53 +42,120 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 	return lit
 }
 
-func (p *Parser) parseFunctionParameters() []*ast.Identifier {
-	identifiers := []*ast.Identifier{}
+func (p *Parser) parseFunctionParameters() []*ast.Parameter {
+	params := []*ast.Parameter{}
 
 	if p.peekToken.Type == lexer.TOKEN_RPAREN {
 		p.nextToken()
-		return identifiers
+		return params
 	}
 
-	p.nextToken()
-
-	// Logic: Parameters must start with $
-	if p.curToken.Type == lexer.TOKEN_DOLLAR {
-		if !p.expectPeek(lexer.TOKEN_IDENT) {
+	for {
+		p.nextToken()
+		param := p.parseFunctionParameter()
+		if param == nil {
 			return nil
 		}
-	} else if p.curToken.Type != lexer.TOKEN_IDENT {
-		// Allow IDENT as well? No, enforce $ for strictness or flexibility?
-		// User writes hunt($a). curToken is $.
-		// If user writes hunt(a), curToken is a.
-		// My parser_extensions_v3 prev logic assumed IDENT.
-		// Let's support both or strictly $. Given Wolf404 is $ var, strictly $.
-		// BUT wait, p.nextToken above advanced to first token.
-		// If first token is $, we need to advance to ident.
-		// If first token is ident ...
-		return nil // Should be $
-	}
-
-	ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-	identifiers = append(identifiers, ident)
 
-	for p.peekToken.Type == lexer.TOKEN_COMMA {
-		p.nextToken() // consume comma
-		p.nextToken() // consume next param start ($)
-		
-		if p.curToken.Type == lexer.TOKEN_DOLLAR {
-			if !p.expectPeek(lexer.TOKEN_IDENT) {
+		if len(params) > 0 {
+			last := params[len(params)-1]
+			if last.Rest {
+				p.errorAt(param.Token, nil, "parameter %s kudu sing pungkasan", last)
+				return nil
+			}
+			if last.Default != nil && param.Default == nil && !param.Rest {
+				p.errorAt(param.Token, nil, "parameter %s tanpa default ora oleh sawise parameter sing nduwe default", param)
 				return nil
 			}
 		}
-		
-		ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-		identifiers = append(identifiers, ident)
+		params = append(params, param)
+
+		if p.peekToken.Type != lexer.TOKEN_COMMA {
+			break
+		}
+		p.nextToken() // consume comma
 	}
 
 	if !p.expectPeek(lexer.TOKEN_RPAREN) {
 		return nil
 	}
 
-	return identifiers
+	return params
+}
+
+// parseFunctionParameter parses $name, $name = default, ...$name or an
+// array/hash pattern such as [$x, $y] that destructures its argument.
+func (p *Parser) parseFunctionParameter() *ast.Parameter {
+	param := &ast.Parameter{Token: p.curToken}
+
+	if p.curToken.Type == lexer.TOKEN_ELLIPSIS {
+		param.Rest = true
+		p.nextToken()
+	}
+	if !param.Rest && (p.curToken.Type == lexer.TOKEN_LBRACKET || p.curToken.Type == lexer.TOKEN_LBRACE) {
+		param.Pattern = p.parseDestructuringPattern()
+		if param.Pattern == nil {
+			return nil
+		}
+	} else {
+		if p.curToken.Type != lexer.TOKEN_DOLLAR {
+			p.errorAt(p.curToken, []string{"$"}, "parameter kudu diwiwiti $, oleh %s", describeToken(p.curToken))
+			return nil
+		}
+		if !p.expectPeek(lexer.TOKEN_IDENT) {
+			return nil
+		}
+		param.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	}
+
+	if p.peekToken.Type == lexer.TOKEN_ASSIGN {
+		if param.Rest {
+			p.errorAt(p.peekToken, nil, "parameter %s ora iso nduwe default", param)
+			return nil
+		}
+		p.nextToken()
+		p.nextToken()
+		param.Default = p.parseExpression(ASSIGN)
+	}
+
+	return param
+}
+
+// parseDestructuringPattern parses an array or hash literal starting at
+// curToken and checks that it only binds variables, as parameters and loop
+// variables declare names rather than assign to arbitrary targets.
+func (p *Parser) parseDestructuringPattern() ast.Expression {
+	var pattern ast.Expression
+	if p.curToken.Type == lexer.TOKEN_LBRACKET {
+		pattern = p.parseArrayLiteral()
+	} else {
+		pattern = p.parseHashLiteral()
+	}
+	if pattern == nil || !p.checkPattern(pattern) {
+		return nil
+	}
+	return pattern
+}
+
+func (p *Parser) checkPattern(pattern ast.Expression) bool {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		return true
+	case *ast.ArrayLiteral:
+		for _, el := range pattern.Elements {
+			if !p.checkPattern(el) {
+				return false
+			}
+		}
+		return true
+	case *ast.HashLiteral:
+		for _, key := range pattern.Keys() {
+			if !p.checkPattern(pattern.Pairs[key]) {
+				return false
+			}
+		}
+		return true
+	}
+	p.errorAt(pattern.Pos(), []string{"$"}, "pola mung oleh isi variabel, oleh %s", pattern)
+	return false
 }
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"strings"
 	"wolf404/compiler/ast"
 	"wolf404/compiler/lexer"
 )
@@ -12,6 +13,48 @@ func (p *Parser) parseTrackStatement() *ast.TrackStatement {
 
 	stmt.Condition = p.parseExpression(LOWEST)
 
+	// track $item in $items / track $k, $v in $hash / track [$a, $b] in $pairs
+	if isLoopTarget(stmt.Condition) &&
+		(p.peekToken.Type == lexer.TOKEN_COMMA || p.peekToken.Type == lexer.TOKEN_IN) {
+		stmt.Value = stmt.Condition
+		stmt.Condition = nil
+
+		if p.peekToken.Type == lexer.TOKEN_COMMA {
+			key, ok := stmt.Value.(*ast.Identifier)
+			if !ok {
+				p.errorAt(p.peekToken, []string{"neng"}, "kunci baleni kudu variabel, oleh %s", stmt.Value)
+				return nil
+			}
+			stmt.Key = key
+			p.nextToken()
+			p.nextToken()
+
+			switch p.curToken.Type {
+			case lexer.TOKEN_LBRACKET, lexer.TOKEN_LBRACE:
+				stmt.Value = p.parseDestructuringPattern()
+				if stmt.Value == nil {
+					return nil
+				}
+			case lexer.TOKEN_DOLLAR:
+				if !p.expectPeek(lexer.TOKEN_IDENT) {
+					return nil
+				}
+				stmt.Value = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+			default:
+				p.errorAt(p.curToken, []string{"$", "[", "{"}, "variabel baleni kudu diwiwiti $, oleh %s", describeToken(p.curToken))
+				return nil
+			}
+		} else if !p.checkPattern(stmt.Value) {
+			return nil
+		}
+
+		if !p.expectPeek(lexer.TOKEN_IN) {
+			return nil
+		}
+		p.nextToken()
+		stmt.Iterable = p.parseExpression(LOWEST)
+	}
+
 	if p.peekToken.Type == lexer.TOKEN_NEWLINE {
 		p.nextToken()
 	}
@@ -26,8 +69,17 @@ func (p *Parser) parseTrackStatement() *ast.TrackStatement {
 	return stmt
 }
 
+// isLoopTarget reports whether exp can name the variable of a for-in loop.
+func isLoopTarget(exp ast.Expression) bool {
+	switch exp.(type) {
+	case *ast.Identifier, *ast.ArrayLiteral, *ast.HashLiteral:
+		return true
+	}
+	return false
+}
+
 func (p *Parser) parseClassStatement() *ast.ClassStatement {
-	stmt := &ast.ClassStatement{Token: p.curToken}
+	stmt := &ast.ClassStatement{Token: p.curToken, Doc: p.takeDoc(p.curToken)}
 
 	p.nextToken() // consume 'mold'
 	
@@ -50,6 +102,14 @@ func (p *Parser) parseClassStatement() *ast.ClassStatement {
 		p.nextToken()
 	}
 
+	// Parse contracts (mold Child : Parent netepi Kontrak, ...)
+	if p.curToken.Type == lexer.TOKEN_IMPLEMENTS {
+		if stmt.Interfaces = p.parseNameList(); stmt.Interfaces == nil {
+			return nil
+		}
+		p.nextToken()
+	}
+
 	if p.peekToken.Type == lexer.TOKEN_NEWLINE {
 		p.nextToken()
 	}
@@ -63,15 +123,161 @@ func (p *Parser) parseClassStatement() *ast.ClassStatement {
 	return stmt
 }
 
+// parseStaticStatement parses statis garap name(...) or statis $name = value.
+func (p *Parser) parseStaticStatement() *ast.StaticStatement {
+	stmt := &ast.StaticStatement{Token: p.curToken}
+
+	switch p.peekToken.Type {
+	case lexer.TOKEN_HUNT:
+		p.nextToken()
+		fn, ok := p.parseFunctionLiteral().(*ast.FunctionLiteral)
+		if !ok {
+			return nil
+		}
+		if fn.Name == "" {
+			p.errorAt(fn.Token, nil, "method statis kudu nduwe jeneng")
+			return nil
+		}
+		stmt.Function = fn
+
+	case lexer.TOKEN_DOLLAR:
+		p.nextToken()
+		if !p.expectPeek(lexer.TOKEN_IDENT) {
+			return nil
+		}
+		stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		if p.peekToken.Type == lexer.TOKEN_ASSIGN {
+			p.nextToken()
+			p.nextToken()
+			stmt.Value = p.parseExpression(LOWEST)
+		}
+
+	default:
+		p.errorAt(p.peekToken, []string{"garap", "$"}, "statis kudu diterusake garap utowo $jeneng, oleh %s", describeToken(p.peekToken))
+		return nil
+	}
+
+	return stmt
+}
+
+// parseConstStatement parses tetep NAME = value.
+func (p *Parser) parseConstStatement() *ast.ConstStatement {
+	stmt := &ast.ConstStatement{Token: p.curToken}
+
+	if !p.expectPeek(lexer.TOKEN_IDENT) {
+		return nil
+	}
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if !p.expectPeek(lexer.TOKEN_ASSIGN) {
+		return nil
+	}
+	p.nextToken()
+	stmt.Value = p.parseExpression(LOWEST)
+
+	return stmt
+}
+
+func (p *Parser) parseSuperExpression() ast.Expression {
+	return &ast.SuperExpression{Token: p.curToken}
+}
+
 func (p *Parser) parseSummonStatement() *ast.SummonStatement {
 	stmt := &ast.SummonStatement{Token: p.curToken}
 
 	if p.peekToken.Type != lexer.TOKEN_STRING {
+		p.errorAt(p.peekToken, []string{"STRING"}, "undang butuh path file, oleh %s", describeToken(p.peekToken))
 		return nil
 	}
 	p.nextToken()
 
 	stmt.Path = &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
 
+	// undang "path" dadi Alias
+	if p.peekToken.Type == lexer.TOKEN_AS {
+		p.nextToken()
+		if !p.expectPeek(lexer.TOKEN_IDENT) {
+			return nil
+		}
+		stmt.Alias = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	}
+
 	return stmt
 }
+
+func (p *Parser) parseTryStatement() *ast.TryStatement {
+	stmt := &ast.TryStatement{Token: p.curToken}
+
+	p.skipToBlock()
+	stmt.Body = p.parseBlockStatement()
+
+	if p.peekToken.Type == lexer.TOKEN_CATCH {
+		p.nextToken() // consume 'catch'
+
+		// catch $e
+		if p.peekToken.Type == lexer.TOKEN_DOLLAR {
+			p.nextToken()
+			if !p.expectPeek(lexer.TOKEN_IDENT) {
+				return nil
+			}
+			stmt.CatchParam = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		}
+
+		p.skipToBlock()
+		stmt.Catch = p.parseBlockStatement()
+	}
+
+	if p.peekToken.Type == lexer.TOKEN_FINALLY {
+		p.nextToken() // consume 'finally'
+		p.skipToBlock()
+		stmt.Finally = p.parseBlockStatement()
+	}
+
+	if stmt.Catch == nil && stmt.Finally == nil {
+		p.errorAt(stmt.Token, []string{"CATCH", "FINALLY"}, "%s butuh cekel utowo pungkasan", stmt.Token.Literal)
+		return nil
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseThrowStatement() *ast.ThrowStatement {
+	stmt := &ast.ThrowStatement{Token: p.curToken}
+
+	p.nextToken()
+	stmt.Value = p.parseExpression(LOWEST)
+
+	return stmt
+}
+
+// skipToBlock advances past the NEWLINE and INDENT that open an indented block.
+func (p *Parser) skipToBlock() {
+	if p.peekToken.Type == lexer.TOKEN_NEWLINE {
+		p.nextToken()
+	}
+	if p.peekToken.Type == lexer.TOKEN_INDENT {
+		p.nextToken()
+	}
+}
+
+// collectDoc adds a /// line to the pending doc comment, starting a new one
+// unless it directly continues the previous line.
+func (p *Parser) collectDoc(tok lexer.Token) {
+	text := strings.TrimPrefix(strings.TrimPrefix(tok.Literal, "///"), " ")
+	if p.doc != "" && p.docLine == tok.Line-1 {
+		p.doc += "\n" + text
+	} else {
+		p.doc = text
+	}
+	p.docLine = tok.Line
+}
+
+// takeDoc returns the doc comment written on the lines right above tok.
+func (p *Parser) takeDoc(tok lexer.Token) string {
+	if p.doc == "" || p.docLine != tok.Line-1 {
+		return ""
+	}
+	doc := p.doc
+	p.doc = ""
+	return doc
+}
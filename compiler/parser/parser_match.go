@@ -0,0 +1,471 @@
+package parser
+
+import (
+	"strings"
+	"wolf404/compiler/ast"
+	"wolf404/compiler/lexer"
+)
+
+// parseMatchExpression parses
+//
+//	cocokno $subject
+//	    pattern, pattern menowo guard => expression
+//	    pattern =>
+//	        block
+func (p *Parser) parseMatchExpression() ast.Expression {
+	exp := &ast.MatchExpression{Token: p.curToken}
+
+	p.nextToken() // consume 'match'
+	exp.Subject = p.parseExpression(LOWEST)
+
+	if p.peekToken.Type == lexer.TOKEN_NEWLINE {
+		p.nextToken()
+	}
+	if p.peekToken.Type != lexer.TOKEN_INDENT {
+		p.errorAt(p.peekToken, []string{"INDENT"}, "cocokno butuh blok pola sing diindentasi, oleh %s", describeToken(p.peekToken))
+		return nil
+	}
+	p.nextToken()
+	p.nextToken() // consume INDENT
+
+	for p.curToken.Type != lexer.TOKEN_DEDENT && p.curToken.Type != lexer.TOKEN_EOF {
+		if p.curToken.Type == lexer.TOKEN_NEWLINE {
+			p.nextToken()
+			continue
+		}
+
+		arm := p.parseMatchArm()
+		if arm != nil {
+			exp.Arms = append(exp.Arms, arm)
+		}
+		if p.recovering {
+			p.synchronize()
+		}
+
+		p.nextToken()
+	}
+
+	// Classes and kontrak can be declared after the cocokno, so the
+	// exhaustiveness check waits for the whole file
+	p.matches = append(p.matches, exp)
+	return exp
+}
+
+func (p *Parser) parseMatchArm() *ast.MatchArm {
+	arm := &ast.MatchArm{Token: p.curToken}
+
+	for {
+		pattern := p.parsePattern()
+		if pattern == nil {
+			return nil
+		}
+		arm.Patterns = append(arm.Patterns, pattern)
+
+		if p.peekToken.Type != lexer.TOKEN_COMMA {
+			break
+		}
+		p.nextToken()
+		p.nextToken()
+	}
+
+	if p.peekToken.Type == lexer.TOKEN_SNIFF {
+		p.nextToken()
+		p.nextToken()
+		arm.Guard = p.parseExpression(LOWEST)
+	}
+
+	if !p.expectPeek(lexer.TOKEN_ARROW) {
+		return nil
+	}
+
+	// pattern => followed by an indented block
+	if p.peekToken.Type == lexer.TOKEN_NEWLINE {
+		p.nextToken()
+		if !p.expectPeek(lexer.TOKEN_INDENT) {
+			return nil
+		}
+		arm.Body = p.parseBlockStatement()
+		return arm
+	}
+
+	p.nextToken()
+	arm.Body = &ast.BlockStatement{Token: p.curToken, Statements: []ast.Statement{}}
+	if stmt := p.parseStatement(); stmt != nil {
+		arm.Body.Statements = append(arm.Body.Statements, stmt)
+	}
+	return arm
+}
+
+func (p *Parser) parsePattern() ast.Pattern {
+	switch p.curToken.Type {
+	case lexer.TOKEN_IDENT:
+		if p.curToken.Literal == "_" {
+			return &ast.WildcardPattern{Token: p.curToken}
+		}
+		if p.peekToken.Type == lexer.TOKEN_DOT {
+			return p.parseConstantPattern()
+		}
+		pattern := &ast.TypePattern{Token: p.curToken, TypeName: p.curToken.Literal}
+		if p.peekToken.Type == lexer.TOKEN_DOLLAR {
+			p.nextToken()
+			if !p.expectPeek(lexer.TOKEN_IDENT) {
+				return nil
+			}
+			pattern.Binding = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		}
+		return pattern
+
+	case lexer.TOKEN_DOLLAR:
+		pattern := &ast.BindingPattern{Token: p.curToken}
+		if !p.expectPeek(lexer.TOKEN_IDENT) {
+			return nil
+		}
+		pattern.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		return pattern
+
+	case lexer.TOKEN_INT, lexer.TOKEN_FLOAT, lexer.TOKEN_STRING, lexer.TOKEN_INTERPOLATED,
+		lexer.TOKEN_TRUE, lexer.TOKEN_FALSE, lexer.TOKEN_NIL, lexer.TOKEN_MINUS:
+		tok := p.curToken
+		value := p.prefixParseFns[tok.Type]()
+		if value == nil {
+			return nil
+		}
+		if prefix, ok := value.(*ast.PrefixExpression); ok {
+			switch prefix.Right.(type) {
+			case *ast.IntegerLiteral, *ast.FloatLiteral:
+			default:
+				p.errorAt(tok, []string{"pattern"}, "unexpected %s, expected a pattern", prefix)
+				return nil
+			}
+		}
+		return &ast.LiteralPattern{Token: tok, Value: value}
+
+	case lexer.TOKEN_LBRACKET:
+		return p.parseArrayPattern()
+
+	case lexer.TOKEN_LBRACE:
+		return p.parseHashPattern()
+	}
+
+	p.errorAt(p.curToken, []string{"pattern"}, "unexpected %s, expected a pattern", describeToken(p.curToken))
+	return nil
+}
+
+// parseConstantPattern parses Gerombolan.KONSTANTA, matched by value like
+// any other literal.
+func (p *Parser) parseConstantPattern() ast.Pattern {
+	class := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	p.nextToken()
+	member := &ast.InfixExpression{Token: p.curToken, Operator: p.curToken.Literal, Left: class}
+	if !p.expectPeek(lexer.TOKEN_IDENT) {
+		return nil
+	}
+	member.Right = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	return &ast.LiteralPattern{Token: class.Token, Value: member}
+}
+
+func (p *Parser) parseArrayPattern() ast.Pattern {
+	pattern := &ast.ArrayPattern{Token: p.curToken}
+
+	for p.peekToken.Type != lexer.TOKEN_RBRACKET {
+		p.nextToken()
+		if pattern.HasRest {
+			p.errorAt(p.curToken, []string{"]"}, "... kudu sing pungkasan neng pola array")
+			return nil
+		}
+
+		if p.curToken.Type == lexer.TOKEN_ELLIPSIS {
+			pattern.HasRest = true
+			if p.peekToken.Type == lexer.TOKEN_DOLLAR {
+				p.nextToken()
+				if !p.expectPeek(lexer.TOKEN_IDENT) {
+					return nil
+				}
+				pattern.Rest = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+			}
+		} else {
+			element := p.parsePattern()
+			if element == nil {
+				return nil
+			}
+			pattern.Elements = append(pattern.Elements, element)
+		}
+
+		if p.peekToken.Type != lexer.TOKEN_RBRACKET && !p.expectPeek(lexer.TOKEN_COMMA) {
+			return nil
+		}
+	}
+	p.nextToken() // consume ]
+
+	return pattern
+}
+
+func (p *Parser) parseHashPattern() ast.Pattern {
+	pattern := &ast.HashPattern{Token: p.curToken}
+
+	for p.peekToken.Type != lexer.TOKEN_RBRACE {
+		p.nextToken()
+		if p.curToken.Type != lexer.TOKEN_STRING && p.curToken.Type != lexer.TOKEN_INT {
+			p.errorAt(p.curToken, []string{"STRING", "INT"}, "unexpected %s, expected a hash key", describeToken(p.curToken))
+			return nil
+		}
+		key := p.prefixParseFns[p.curToken.Type]()
+
+		if !p.expectPeek(lexer.TOKEN_COLON) {
+			return nil
+		}
+		p.nextToken()
+		value := p.parsePattern()
+		if value == nil {
+			return nil
+		}
+		pattern.Keys = append(pattern.Keys, key)
+		pattern.Values = append(pattern.Values, value)
+
+		if p.peekToken.Type != lexer.TOKEN_RBRACE && !p.expectPeek(lexer.TOKEN_COMMA) {
+			return nil
+		}
+	}
+	p.nextToken() // consume }
+
+	return pattern
+}
+
+// declarations holds the gerombolan and kontrak declared at the top level of
+// a file, in declaration order, for the exhaustiveness check.
+type declarations struct {
+	classes     []*ast.ClassStatement
+	classByName map[string]*ast.ClassStatement
+	interfaces  map[string]*ast.InterfaceStatement
+}
+
+func collectDeclarations(program *ast.Program) *declarations {
+	decls := &declarations{
+		classByName: map[string]*ast.ClassStatement{},
+		interfaces:  map[string]*ast.InterfaceStatement{},
+	}
+	for _, stmt := range program.Statements {
+		if export, ok := stmt.(*ast.ExportStatement); ok {
+			stmt = export.Statement
+		}
+		switch stmt := stmt.(type) {
+		case *ast.ClassStatement:
+			decls.classes = append(decls.classes, stmt)
+			decls.classByName[stmt.Name.Value] = stmt
+		case *ast.InterfaceStatement:
+			decls.interfaces[stmt.Name.Value] = stmt
+		}
+	}
+	return decls
+}
+
+// constants lists the tetep names of class and the classes it extends.
+func (d *declarations) constants(class *ast.ClassStatement) []string {
+	names := []string{}
+	seen := map[string]bool{}
+	for c := class; c != nil && !seen[c.Name.Value]; c = d.super(c) {
+		seen[c.Name.Value] = true
+		for _, stmt := range c.Body.Statements {
+			if constant, ok := stmt.(*ast.ConstStatement); ok {
+				names = append(names, constant.Name.Value)
+			}
+		}
+	}
+	return names
+}
+
+func (d *declarations) super(class *ast.ClassStatement) *ast.ClassStatement {
+	if class.SuperClass == nil {
+		return nil
+	}
+	return d.classByName[class.SuperClass.Value]
+}
+
+// isA reports whether a value of class would match a type pattern naming
+// name: the class itself, one of its parents, or a kontrak it netepi.
+func (d *declarations) isA(class *ast.ClassStatement, name string) bool {
+	seen := map[string]bool{}
+	for c := class; c != nil && !seen[c.Name.Value]; c = d.super(c) {
+		seen[c.Name.Value] = true
+		if c.Name.Value == name {
+			return true
+		}
+		for _, iface := range c.Interfaces {
+			if d.extends(iface.Value, name, map[string]bool{}) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// extends reports whether kontrak iface is name or inherits from it.
+func (d *declarations) extends(iface, name string, seen map[string]bool) bool {
+	if iface == name {
+		return true
+	}
+	stmt, ok := d.interfaces[iface]
+	if !ok || seen[iface] {
+		return false
+	}
+	seen[iface] = true
+	for _, parent := range stmt.Parents {
+		if d.extends(parent.Value, name, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkMatches warns about each cocokno that leaves a case of an enum-like
+// subject out, so that value would fall through to a MatchError.
+func (p *Parser) checkMatches(program *ast.Program) {
+	if len(p.matches) == 0 {
+		return
+	}
+	decls := collectDeclarations(program)
+	for _, exp := range p.matches {
+		if missing := decls.missingCases(exp); len(missing) > 0 {
+			p.warnAt(exp.Token, "cocokno ora lengkap: %s durung ono polane, tambahno utowo nganggo pola _", strings.Join(missing, ", "))
+		}
+	}
+}
+
+// missingCases lists the cases exp leaves out when every pattern is one of
+// bener/salah, a constant of a single gerombolan, or a gerombolan that
+// netepi a kontrak declared in the file. Other subjects can take any value,
+// so missing cases can't be told apart from ones that never occur and
+// nothing is reported. Arms with a menowo guard don't count as covering
+// their patterns.
+func (d *declarations) missingCases(exp *ast.MatchExpression) []string {
+	var booleans, constants, types []ast.Pattern
+	covered := map[string]bool{}
+
+	for _, arm := range exp.Arms {
+		for _, pattern := range arm.Patterns {
+			name := ""
+			switch pattern := pattern.(type) {
+			case *ast.WildcardPattern, *ast.BindingPattern:
+				if arm.Guard == nil {
+					return nil
+				}
+				continue
+			case *ast.LiteralPattern:
+				switch value := pattern.Value.(type) {
+				case *ast.Boolean:
+					booleans = append(booleans, pattern)
+					name = booleanName(value.Value)
+				case *ast.InfixExpression:
+					constants = append(constants, pattern)
+					name = value.Left.String() + "." + value.Right.String()
+				default:
+					return nil
+				}
+			case *ast.TypePattern:
+				types = append(types, pattern)
+				name = pattern.TypeName
+			default:
+				return nil
+			}
+			if arm.Guard == nil {
+				covered[name] = true
+			}
+		}
+	}
+
+	switch {
+	case len(booleans) > 0 && len(constants) == 0 && len(types) == 0:
+		return uncovered([]string{"bener", "salah"}, covered)
+	case len(constants) > 0 && len(booleans) == 0 && len(types) == 0:
+		return d.missingConstants(constants, covered)
+	case len(types) > 0 && len(booleans) == 0 && len(constants) == 0:
+		return d.missingImplementers(types, covered)
+	}
+	return nil
+}
+
+func booleanName(value bool) string {
+	if value {
+		return "bener"
+	}
+	return "salah"
+}
+
+func (d *declarations) missingConstants(patterns []ast.Pattern, covered map[string]bool) []string {
+	className := ""
+	for _, pattern := range patterns {
+		name := pattern.(*ast.LiteralPattern).Value.(*ast.InfixExpression).Left.String()
+		if className != "" && name != className {
+			return nil
+		}
+		className = name
+	}
+	class, ok := d.classByName[className]
+	if !ok {
+		return nil
+	}
+
+	cases := []string{}
+	for _, constant := range d.constants(class) {
+		cases = append(cases, className+"."+constant)
+	}
+	return uncovered(cases, covered)
+}
+
+// missingImplementers picks the kontrak every named type netepi and lists
+// its implementers that no unguarded type pattern matches.
+func (d *declarations) missingImplementers(patterns []ast.Pattern, covered map[string]bool) []string {
+	for _, class := range d.classes {
+		for _, iface := range class.Interfaces {
+			kontrak := iface.Value
+			if _, ok := d.interfaces[kontrak]; !ok || !d.allWithin(patterns, kontrak) {
+				continue
+			}
+
+			missing := []string{}
+			for _, implementer := range d.classes {
+				if !d.isA(implementer, kontrak) || d.matchesAny(implementer, covered) {
+					continue
+				}
+				missing = append(missing, implementer.Name.Value)
+			}
+			return missing
+		}
+	}
+	return nil
+}
+
+// allWithin reports whether every pattern names kontrak or a gerombolan
+// that netepi it.
+func (d *declarations) allWithin(patterns []ast.Pattern, kontrak string) bool {
+	for _, pattern := range patterns {
+		name := pattern.(*ast.TypePattern).TypeName
+		if d.extends(name, kontrak, map[string]bool{}) {
+			continue
+		}
+		class, ok := d.classByName[name]
+		if !ok || !d.isA(class, kontrak) {
+			return false
+		}
+	}
+	return true
+}
+
+func (d *declarations) matchesAny(class *ast.ClassStatement, covered map[string]bool) bool {
+	for name := range covered {
+		if d.isA(class, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func uncovered(cases []string, covered map[string]bool) []string {
+	missing := []string{}
+	for _, name := range cases {
+		if !covered[name] {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,54 @@
+package parser
+
+import (
+	"wolf404/compiler/ast"
+)
+
+// parseExportStatement parses ekspor followed by a declaration: a
+// gerombolan, kontrak, sifat or $name = value.
+func (p *Parser) parseExportStatement() *ast.ExportStatement {
+	stmt := &ast.ExportStatement{Token: p.curToken}
+
+	p.nextToken()
+	stmt.Statement = p.parseStatement()
+	if p.recovering {
+		return nil
+	}
+
+	stmt.Name = exportedName(stmt.Statement)
+	if stmt.Name == "" {
+		p.errorAt(stmt.Token, nil, "ekspor mung kanggo gerombolan, kontrak, sifat utowo $jeneng = nilai")
+		return nil
+	}
+
+	return stmt
+}
+
+// exportedName returns the name stmt binds at the top of a file, or "" when
+// it is not a declaration that can be exported.
+func exportedName(stmt ast.Statement) string {
+	switch stmt := stmt.(type) {
+	case *ast.ClassStatement:
+		if stmt != nil && stmt.Name != nil {
+			return stmt.Name.Value
+		}
+	case *ast.InterfaceStatement:
+		if stmt != nil && stmt.Name != nil {
+			return stmt.Name.Value
+		}
+	case *ast.TraitStatement:
+		if stmt != nil && stmt.Name != nil {
+			return stmt.Name.Value
+		}
+	case *ast.ExpressionStatement:
+		if stmt == nil {
+			return ""
+		}
+		if exp, ok := stmt.Expression.(*ast.InfixExpression); ok && exp.Operator == "=" {
+			if ident, ok := exp.Left.(*ast.Identifier); ok {
+				return ident.Value
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,477 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+	"wolf404/compiler/ast"
+	"wolf404/compiler/lexer"
+)
+
+// parseTest is a program and its String() form after parsing.
+type parseTest struct {
+	input    string
+	expected string
+}
+
+// errorTest is a program that must fail to parse with a message
+// containing expected.
+type errorTest struct {
+	input    string
+	expected string
+}
+
+func parse(t *testing.T, input string) *ast.Program {
+	t.Helper()
+	p := New(lexer.NewWithFile(input, "test.wlf"))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parse errors for %q: %v", input, p.Errors())
+	}
+	return program
+}
+
+func runParseTests(t *testing.T, tests []parseTest) {
+	t.Helper()
+	for _, tt := range tests {
+		if got := parse(t, tt.input).String(); got != tt.expected {
+			t.Errorf("%q\n got: %s\nwant: %s", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func runErrorTests(t *testing.T, tests []errorTest) {
+	t.Helper()
+	for _, tt := range tests {
+		p := New(lexer.NewWithFile(tt.input, "test.wlf"))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("%q: expected a parse error containing %q", tt.input, tt.expected)
+			continue
+		}
+		if got := p.Errors()[0].Message; !strings.Contains(got, tt.expected) {
+			t.Errorf("%q\n got error: %s\nwant error containing: %s", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestFloatLiterals(t *testing.T) {
+	runParseTests(t, []parseTest{
+		{"1.5", "1.5"},
+		{"1.5 + 2", "(1.5 + 2)"},
+		{"2 ** 3 ** 2", "(2 ** (3 ** 2))"},
+	})
+}
+
+func TestPrefixAndLogicalPrecedence(t *testing.T) {
+	runParseTests(t, []parseTest{
+		{"-$a", "(-a)"},
+		{"ora $a", "(not a)"},
+		{"$a > 0 lan $b > 0", "((a > 0) and (b > 0))"},
+		{"$a lan $b utowo $c", "((a and b) or c)"},
+		{"$a utowo $b lan $c", "(a or (b and c))"},
+		{"-$a * $b", "((-a) * b)"},
+	})
+}
+
+func TestComparisonPrecedence(t *testing.T) {
+	runParseTests(t, []parseTest{
+		{"$a + $b % $c", "(a + (b % c))"},
+		{"$a <= $b + 1", "(a <= (b + 1))"},
+		{"$a * $b ** 2", "(a * (b ** 2))"},
+		{"$a == $b >= $c", "(a == (b >= c))"},
+	})
+}
+
+func TestForInStatements(t *testing.T) {
+	runParseTests(t, []parseTest{
+		{"baleni $x neng $xs\n    $x", "track x in xs x"},
+		{"baleni $k, $v neng $h\n    $v", "track k, v in h v"},
+		{"baleni $i neng deret(0, 10, 2)\n    $i", "track i in deret(0, 10, 2) i"},
+		{"track $i < 3\n    $i", "track (i < 3) i"},
+	})
+	runErrorTests(t, []errorTest{
+		{"baleni $x, 1 neng $xs\n    $x", "variabel baleni kudu diwiwiti $"},
+	})
+}
+
+func TestBreakAndContinueStatements(t *testing.T) {
+	runParseTests(t, []parseTest{
+		{"baleni $x neng $xs\n    mandheg", "track x in xs mandheg"},
+		{"track bener\n    continue", "track bener continue"},
+	})
+}
+
+func TestElseIfExpressions(t *testing.T) {
+	runParseTests(t, []parseTest{
+		{"menowo $a\n    1\nyenora menowo $b\n    2\nyenora\n    3", "sniff a 1 missing sniff b 2 missing 3"},
+	})
+	runErrorTests(t, []errorTest{
+		{"yenora\n    1", "expected an expression"},
+	})
+}
+
+func TestIndexAssignmentTargets(t *testing.T) {
+	runParseTests(t, []parseTest{
+		{`$a[0] = 1`, "((a[0]) = 1)"},
+		{`$this.config["db"]["path"] = $p`, `((((this . config)["db"])["path"]) = p)`},
+	})
+}
+
+func TestCompoundAssignmentOperators(t *testing.T) {
+	runParseTests(t, []parseTest{
+		{"$i += 1", "(i += 1)"},
+		{"$i -= $j * 2", "(i -= (j * 2))"},
+		{`$h["n"] %= 3`, `((h["n"]) %= 3)`},
+		// $i++ and $i-- desugar to += 1 and -= 1
+		{"$i++", "(i += 1)"},
+		{"$i--", "(i -= 1)"},
+	})
+}
+
+func TestTryAndThrowStatements(t *testing.T) {
+	p := parse(t, "cobo\n    f()\ncekel $e\n    uncalno $e\npungkasan\n    g()")
+	if len(p.Statements) != 1 {
+		t.Fatalf("expected 1 statement, got %d", len(p.Statements))
+	}
+	stmt, ok := p.Statements[0].(*ast.TryStatement)
+	if !ok {
+		t.Fatalf("expected *ast.TryStatement, got %T", p.Statements[0])
+	}
+	if stmt.CatchParam == nil || stmt.CatchParam.Value != "e" {
+		t.Errorf("catch param: got %v, want e", stmt.CatchParam)
+	}
+	if stmt.Finally == nil {
+		t.Errorf("pungkasan block missing")
+	}
+	if _, ok := stmt.Catch.Statements[0].(*ast.ThrowStatement); !ok {
+		t.Errorf("expected uncalno in cekel, got %T", stmt.Catch.Statements[0])
+	}
+}
+
+func TestParseErrorPositions(t *testing.T) {
+	p := New(lexer.NewWithFile("$x = 1\n$y = 2 +\nsay(1)", "main.wlf"))
+	p.ParseProgram()
+	if len(p.Errors()) == 0 {
+		t.Fatalf("expected a parse error")
+	}
+	err := p.Errors()[0]
+	if got := err.Position(); got != "main.wlf:2:9" {
+		t.Errorf("position: got %s, want main.wlf:2:9", got)
+	}
+	if got := err.Snippet("$x = 1\n$y = 2 +\nsay(1)"); got != "$y = 2 +\n        ^" {
+		t.Errorf("snippet: got %q", got)
+	}
+}
+
+func TestParseErrorRecovery(t *testing.T) {
+	// One error per broken statement, and parsing carries on after each
+	p := New(lexer.NewWithFile("$a = )\n$b = 2\n$c = (\n$d = 4", "test.wlf"))
+	program := p.ParseProgram()
+	if got := len(p.Errors()); got != 2 {
+		t.Fatalf("expected 2 errors, got %d: %v", got, p.Errors())
+	}
+	if line := p.Errors()[1].Token.Line; line != 3 {
+		t.Errorf("second error on line %d, want 3", line)
+	}
+	if got := program.String(); !strings.Contains(got, "(b = 2)") || !strings.Contains(got, "(d = 4)") {
+		t.Errorf("statements after the errors were lost: %s", got)
+	}
+
+	var input strings.Builder
+	for i := 0; i < 20; i++ {
+		input.WriteString("$x = )\n")
+	}
+	p = New(lexer.NewWithFile(input.String(), "test.wlf"))
+	p.ParseProgram()
+	errs := p.Errors()
+	if len(errs) != MaxErrors+1 {
+		t.Fatalf("expected %d errors and a note, got %d", MaxErrors, len(errs))
+	}
+	for i, err := range errs[:MaxErrors] {
+		if err.Message == "too many errors" || err.Token.Line != i+1 {
+			t.Errorf("error %d: got %s %q, want the real error on line %d", i, err.Position(), err.Message, i+1)
+		}
+	}
+	// The note points at the first error that was not reported
+	if note := errs[MaxErrors]; note.Message != "too many errors" || note.Token.Line != MaxErrors+1 {
+		t.Errorf("note: got %s %q, want \"too many errors\" on line %d", note.Position(), note.Message, MaxErrors+1)
+	}
+
+	// Exactly MaxErrors errors need no note
+	p = New(lexer.NewWithFile(strings.Repeat("$x = )\n", MaxErrors), "test.wlf"))
+	p.ParseProgram()
+	if got := len(p.Errors()); got != MaxErrors || p.Errors()[MaxErrors-1].Message == "too many errors" {
+		t.Errorf("expected %d real errors, got %d", MaxErrors, got)
+	}
+}
+
+func TestParseErrorExpected(t *testing.T) {
+	runErrorTests(t, []errorTest{
+		{"f(1, 2", "expected"},
+		{"$a = ", "expected an expression"},
+		{"[1, 2", "expected"},
+	})
+}
+
+func TestStringLiteralErrors(t *testing.T) {
+	runErrorTests(t, []errorTest{
+		{`"ora ditutup`, "string ora ditutup"},
+		{"\"\"\"ora\nditutup", "ora ditutup"},
+		{"`ora ditutup", "ora ditutup"},
+		{`"\q"`, `\q`},
+	})
+}
+
+func TestInterpolationSegments(t *testing.T) {
+	tests := []struct {
+		input string
+		parts []string
+	}{
+		{`"Halo $jeneng!"`, []string{`"Halo "`, "jeneng", `"!"`}},
+		{`"$name.wlf"`, []string{"name", `".wlf"`}},
+		{`"$this.name."`, []string{"(this . name)", `"."`}},
+		{`"{$a + 1}"`, []string{"(a + 1)"}},
+		{`"$row[0]"`, []string{"(row[0])"}},
+	}
+	for _, tt := range tests {
+		stmt := parse(t, tt.input).Statements[0].(*ast.ExpressionStatement)
+		str, ok := stmt.Expression.(*ast.InterpolatedString)
+		if !ok {
+			t.Errorf("%s: expected *ast.InterpolatedString, got %T", tt.input, stmt.Expression)
+			continue
+		}
+		var got []string
+		for _, part := range str.Parts {
+			if lit, ok := part.(*ast.StringLiteral); ok {
+				got = append(got, `"`+lit.Value+`"`)
+			} else {
+				got = append(got, part.String())
+			}
+		}
+		if strings.Join(got, "|") != strings.Join(tt.parts, "|") {
+			t.Errorf("%s: got parts %q, want %q", tt.input, got, tt.parts)
+		}
+	}
+
+	runErrorTests(t, []errorTest{
+		{`"{$a +}"`, "expected an expression"},
+		{`"{$a"`, "ora ditutup"},
+	})
+}
+
+func TestUnicodeColumns(t *testing.T) {
+	// Columns count characters, not bytes
+	p := New(lexer.NewWithFile(`$s = "héé" +`+"\nsay(1)", "test.wlf"))
+	p.ParseProgram()
+	if len(p.Errors()) == 0 {
+		t.Fatalf("expected a parse error")
+	}
+	if got := p.Errors()[0].Position(); got != "test.wlf:1:13" {
+		t.Errorf("got %s, want test.wlf:1:13", got)
+	}
+
+	runParseTests(t, []parseTest{
+		{"$jeneng_é = 1", "(jeneng_é = 1)"},
+	})
+}
+
+func TestComments(t *testing.T) {
+	runParseTests(t, []parseTest{
+		{"$a = 1 // komentar\n$b = 2", "(a = 1)(b = 2)"},
+		{"$a = /* tengah */ 1", "(a = 1)"},
+		{"/* baris\n   akeh */\n$a = 1", "(a = 1)"},
+		{"//// dudu doc\n$a = 1", "(a = 1)"},
+	})
+	runErrorTests(t, []errorTest{
+		{"$a = 1 /* ora ditutup", "komentar /* ora ditutup"},
+	})
+}
+
+func TestDocComments(t *testing.T) {
+	program := parse(t, `/// Nyapa wong.
+/// Mbalekake salam.
+garap sapa($jeneng)
+    balekno $jeneng
+
+/// Kewan alas.
+gerombolan Serigala
+    /// Muni.
+    garap auman()
+        balekno "auu"
+
+// biasa
+garap liyane()
+    balekno 1`)
+
+	fn := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	if fn.Doc != "Nyapa wong.\nMbalekake salam." {
+		t.Errorf("garap doc: got %q", fn.Doc)
+	}
+	class := program.Statements[1].(*ast.ClassStatement)
+	if class.Doc != "Kewan alas." {
+		t.Errorf("gerombolan doc: got %q", class.Doc)
+	}
+	method := class.Body.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	if method.Doc != "Muni." {
+		t.Errorf("method doc: got %q", method.Doc)
+	}
+	other := program.Statements[2].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	if other.Doc != "" {
+		t.Errorf("plain comment became a doc: %q", other.Doc)
+	}
+}
+
+func TestSliceExpressions(t *testing.T) {
+	runParseTests(t, []parseTest{
+		{"$a[1:3]", "(a[1:3])"},
+		{"$a[:2]", "(a[:2])"},
+		{"$a[1:]", "(a[1:])"},
+		{"$a[::-1]", "(a[::(-1)])"},
+		{"$a[$i + 1:$j]", "(a[(i + 1):j])"},
+	})
+}
+
+func TestFunctionParameterSyntax(t *testing.T) {
+	runErrorTests(t, []errorTest{
+		{"$f = garap($a = 1, $b)\n    1", "parameter"},
+		{"$f = garap(...$a, $b)\n    1", "..."},
+	})
+
+	program := parse(t, "$f = garap($a, $b = 2, ...$c)\n    1\n$f(1, b: 3)")
+	fn := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.InfixExpression).Right.(*ast.FunctionLiteral)
+	if len(fn.Parameters) != 3 || fn.Parameters[1].Default == nil || !fn.Parameters[2].Rest {
+		t.Errorf("parameters parsed wrong: %v", fn.Parameters)
+	}
+	call := program.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.CallExpression)
+	if named, ok := call.Arguments[1].(*ast.NamedArgument); !ok || named.Name != "b" {
+		t.Errorf("expected named argument b, got %v", call.Arguments[1])
+	}
+}
+
+func TestDestructuringPatterns(t *testing.T) {
+	runParseTests(t, []parseTest{
+		{"[$a, $b] = $xs", "([a, b] = xs)"},
+		{`{"k": $v} = $h`, `({"k":v} = h)`},
+	})
+	runErrorTests(t, []errorTest{
+		{"baleni [$a, 1] neng $xs\n    $a", "pola mung oleh isi variabel"},
+		{"$f = garap([$a + 1])\n    $a", "pola mung oleh isi variabel"},
+	})
+}
+
+func TestMatchExhaustivenessWarning(t *testing.T) {
+	status := "gerombolan Status\n    tetep AKTIF = 1\n    tetep MATI = 0\n"
+	shapes := "kontrak Bentuk\n    garap jembar()\n" +
+		"gerombolan Bunder netepi Bentuk\n    garap jembar()\n        balekno 1\n" +
+		"gerombolan Kotak netepi Bentuk\n    garap jembar()\n        balekno 2\n"
+	tests := []struct {
+		input string
+		warns bool
+	}{
+		{"cocokno $x\n    bener => 1", true},
+		{"cocokno $x\n    bener => 1\n    salah => 0", false},
+		{"cocokno $x\n    bener => 1\n    salah menowo $y => 0", true},
+		// Other subjects can't be checked, so nothing is reported
+		{"cocokno $x\n    200 => 1", false},
+		{`cocokno $x` + "\n    {\"error\": bener} => 1", false},
+		{"cocokno $x\n    STRING => 1", false},
+		{"cocokno $x\n    bener => 1\n    _ => 0", false},
+		// Constants of one gerombolan
+		{status + "cocokno $x\n    Status.AKTIF => 1", true},
+		{status + "cocokno $x\n    Status.AKTIF, Status.MATI => 1", false},
+		{status + "cocokno $x\n    Status.AKTIF => 1\n    Status.MATI menowo $y => 0", true},
+		{"cocokno $x\n    Status.AKTIF => 1\n" + status, true},
+		{"cocokno $x\n    Liyane.AKTIF => 1", false},
+		// Gerombolan that netepi a kontrak
+		{shapes + "cocokno $x\n    Bunder $b => 1", true},
+		{shapes + "cocokno $x\n    Bunder => 1\n    Kotak => 2", false},
+		{shapes + "cocokno $x\n    Bentuk => 1", false},
+		{shapes + "cocokno $x\n    Bunder => 1\n    STRING => 2", false},
+	}
+	for _, tt := range tests {
+		p := New(lexer.NewWithFile(tt.input, "test.wlf"))
+		p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("%q: parse errors: %v", tt.input, p.Errors())
+		}
+		if got := len(p.Warnings()) > 0; got != tt.warns {
+			t.Errorf("%q: warned=%v, want %v", tt.input, got, tt.warns)
+		}
+	}
+}
+
+func TestMatchPatternErrors(t *testing.T) {
+	runErrorTests(t, []errorTest{
+		{"cocokno $x\n    $a + 1 => 1", "expected next token to be =>"},
+		{"cocokno $x\n    [...$a, $b] => 1", "... kudu sing pungkasan"},
+		{"cocokno $x\n    {$k: 1} => 1", "expected a hash key"},
+		{"cocokno $x 1", "cocokno butuh blok pola"},
+	})
+}
+
+func TestScopeStatements(t *testing.T) {
+	runParseTests(t, []parseTest{
+		{"lokal $x = 1", "local $x = 1"},
+		{"jagad $total", "global $total"},
+	})
+}
+
+func TestSuperExpressions(t *testing.T) {
+	runParseTests(t, []parseTest{
+		{"induk.init($x)", "(super . init)(x)"},
+		{"super.suara()", "(super . suara)()"},
+	})
+}
+
+func TestStaticDeclarations(t *testing.T) {
+	program := parse(t, "gerombolan M\n    tetep A = 1\n    statis $b = 2\n    statis garap f()\n        1")
+	class := program.Statements[0].(*ast.ClassStatement)
+	if len(class.Body.Statements) != 3 {
+		t.Fatalf("expected 3 members, got %d", len(class.Body.Statements))
+	}
+}
+
+func TestContractAndTraitDeclarations(t *testing.T) {
+	program := parse(t, "kontrak K : A, B\n    garap f($x)\n    garap g()\nsifat S\n    garap h()\n        1\ngerombolan C : P netepi K\n    nyampur S\n    garap f($x)\n        1")
+	iface := program.Statements[0].(*ast.InterfaceStatement)
+	if len(iface.Parents) != 2 || len(iface.Methods) != 2 {
+		t.Errorf("kontrak parsed wrong: %d parents, %d methods", len(iface.Parents), len(iface.Methods))
+	}
+	if _, ok := program.Statements[1].(*ast.TraitStatement); !ok {
+		t.Errorf("expected *ast.TraitStatement, got %T", program.Statements[1])
+	}
+	class := program.Statements[2].(*ast.ClassStatement)
+	if class.SuperClass == nil || len(class.Interfaces) != 1 {
+		t.Errorf("gerombolan parsed wrong: %v", class)
+	}
+
+	runErrorTests(t, []errorTest{
+		{"kontrak K\n    garap f()\n        1", "kontrak"},
+		{"kontrak K\n    $x = 1", "kontrak"},
+	})
+}
+
+func TestParseWithPreservedComments(t *testing.T) {
+	input := `// judul
+$f = garap($a) // jejer
+    // njero
+    /* blok */ balekno $a
+/// Kewan.
+gerombolan K
+    garap f()
+        1
+$f(1)`
+	l := lexer.NewWithFile(input, "test.wlf")
+	l.PreserveComments()
+	p := New(l)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parse errors: %v", p.Errors())
+	}
+	if got, want := program.String(), parse(t, input).String(); got != want {
+		t.Errorf("comment tokens changed the parse\n got: %s\nwant: %s", got, want)
+	}
+	if class := program.Statements[1].(*ast.ClassStatement); class.Doc != "Kewan." {
+		t.Errorf("gerombolan doc: got %q", class.Doc)
+	}
+}
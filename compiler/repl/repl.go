@@ -22,26 +22,28 @@ func Start(in io.Reader, out io.Writer) {
 			return
 		}
 		line := scanner.Text()
-		l := lexer.New(line)
+		l := lexer.NewWithFile(line, "<repl>")
 		p := parser.New(l)
 
 		program := p.ParseProgram()
 		if len(p.Errors()) != 0 {
-			printParserErrors(out, p.Errors())
+			printParserErrors(out, p.Errors(), line)
 			continue
 		}
+		io.WriteString(out, parser.FormatWarnings(p.Warnings(), line))
 
 		evaluated := evaluator.Eval(program, env)
-		if evaluated != nil {
+		if err, ok := evaluated.(*object.Error); ok {
+			io.WriteString(out, evaluator.FormatTraceback(err))
+			io.WriteString(out, "\n")
+		} else if evaluated != nil {
 			io.WriteString(out, evaluated.Inspect())
 			io.WriteString(out, "\n")
 		}
 	}
 }
 
-func printParserErrors(out io.Writer, errors []string) {
+func printParserErrors(out io.Writer, errors []*parser.ParseError, source string) {
 	io.WriteString(out, "🐺 Woops! Parser error:\n")
-	for _, msg := range errors {
-		io.WriteString(out, "\t"+msg+"\n")
-	}
+	io.WriteString(out, parser.FormatErrors(errors, source))
 }